@@ -21,7 +21,8 @@ const (
 	MetricEmptyPlaceholder = "-"
 	// MetricIDPlaceholder is a string placeholder for ID section of operation if any
 	MetricIDPlaceholder = "-id-"
-	// MetricOperationsMaxLength is maximum number of operations allowed in one bucket
+	// MetricOperationsMaxLength is default number of operations in one bucket, buckets with other depth
+	// can be built with NewPlainWithDepth, NewPrometheusWithDepth and NewHTTPRequestWithDepth
 	MetricOperationsMaxLength = 3
 )
 
@@ -19,31 +19,54 @@ type HTTPMetricNameAlterCallback func(metricParts *MetricOperation, r *http.Requ
 type HTTPRequest struct {
 	*Plain
 
-	r        *http.Request
-	callback HTTPMetricNameAlterCallback
+	r         *http.Request
+	callback  HTTPMetricNameAlterCallback
+	operation *MetricOperation
 }
 
-// NewHTTPRequest builds and returns new HTTPRequest instance
+// NewHTTPRequest builds and returns new HTTPRequest instance with MetricOperationsMaxLength operations
 func NewHTTPRequest(section string, r *http.Request, success bool, callback HTTPMetricNameAlterCallback, unicode bool) *HTTPRequest {
-	operation := BuildHTTPRequestMetricOperation(r, callback)
-	return &HTTPRequest{NewPlain(section, operation, success, unicode), r, callback}
+	return NewHTTPRequestWithDepth(section, r, success, callback, unicode, MetricOperationsMaxLength)
+}
+
+// NewHTTPRequestWithDepth builds and returns new HTTPRequest instance with method and depth-1 path levels operations
+func NewHTTPRequestWithDepth(section string, r *http.Request, success bool, callback HTTPMetricNameAlterCallback, unicode bool, depth int) *HTTPRequest {
+	operation := BuildHTTPRequestMetricOperationWithDepth(r, callback, depth)
+	return &HTTPRequest{NewPlainWithDepth(section, operation, success, unicode, depth), r, callback, operation}
+}
+
+// Route returns request route built from metric operation path levels, the same that are used in metric name,
+// in the form "/<path-level-0>/<path-level-1>", trailing empty levels are skipped, e.g. "/" for root path
+func (b *HTTPRequest) Route() string {
+	levels := b.operation.Operations()[1:]
+	for len(levels) > 0 && levels[len(levels)-1] == MetricEmptyPlaceholder {
+		levels = levels[:len(levels)-1]
+	}
+
+	return "/" + strings.Join(levels, "/")
 }
 
 // BuildHTTPRequestMetricOperation builds metric operation from HTTP request
 func BuildHTTPRequestMetricOperation(r *http.Request, callback HTTPMetricNameAlterCallback) *MetricOperation {
-	metricParts := NewMetricOperation(strings.ToLower(r.Method), MetricEmptyPlaceholder, MetricEmptyPlaceholder)
+	return BuildHTTPRequestMetricOperationWithDepth(r, callback, MetricOperationsMaxLength)
+}
+
+// BuildHTTPRequestMetricOperationWithDepth builds metric operation from HTTP request with method and depth-1
+// path levels, zero or negative depth means MetricOperationsMaxLength
+func BuildHTTPRequestMetricOperationWithDepth(r *http.Request, callback HTTPMetricNameAlterCallback, depth int) *MetricOperation {
+	metricParts := newMetricOperationWithDepth(depth, strings.ToLower(r.Method))
 	if r.URL.Path != "/" {
 		partsFilled := 1
 		for _, fragment := range strings.Split(r.URL.Path, "/") {
+			if partsFilled >= len(metricParts.operations) {
+				break
+			}
 			if fragment == "" {
 				continue
 			}
 
 			metricParts.operations[partsFilled] = fragment
 			partsFilled++
-			if partsFilled >= len(metricParts.operations) {
-				break
-			}
 		}
 	}
 
@@ -306,3 +306,55 @@ func TestHttpRequest_MetricWithSuffix_customSection(t *testing.T) {
 		assert.Equal(t, data.Metric, b.MetricWithSuffix())
 	}
 }
+
+func TestHttpRequest_Route(t *testing.T) {
+	dataProvider := []struct {
+		Method string
+		Path   string
+		Route  string
+	}{
+		{"GET", "/", "/"},
+		{"GET", "/foo", "/foo"},
+		{"GET", "/foo/", "/foo"},
+		{"POST", "/foo/bar/baz", "/foo/bar"},
+		{"GET", "/token/client_credentials", "/token/client_credentials"},
+	}
+
+	for _, data := range dataProvider {
+		r := &http.Request{Method: data.Method, URL: &url.URL{Path: data.Path}}
+		b := NewHTTPRequest(SectionRequest, r, true, nil, true)
+		assert.Equal(t, data.Route, b.Route(), data.Path)
+	}
+
+	callback := func(operation *MetricOperation, r *http.Request) *MetricOperation {
+		return NewMetricOperation(operation.Operations()[0], "users", MetricIDPlaceholder)
+	}
+	r := &http.Request{Method: http.MethodGet, URL: &url.URL{Path: "/users/123"}}
+	assert.Equal(t, "/users/"+MetricIDPlaceholder, NewHTTPRequest(SectionRequest, r, true, callback, true).Route())
+}
+
+func TestHttpRequest_MetricWithDepth(t *testing.T) {
+	dataProvider := []struct {
+		Path   string
+		Depth  int
+		Metric string
+		Route  string
+	}{
+		{"/", 5, "request.get.-.-.-.-", "/"},
+		{"/users/123/orders/456", 5, "request.get.users.-id-.orders.456", "/users/-id-/orders/456"},
+		{"/users/123/orders/456/items", 5, "request.get.users.-id-.orders.456", "/users/-id-/orders/456"},
+		{"/users/123/orders", 3, "request.get.users.-id-", "/users/-id-"},
+		{"/users/123/orders", 2, "request.get.users", "/users"},
+		{"/users/123/orders", 1, "request.get", "/"},
+	}
+
+	callback := NewHasIDAtSecondLevelCallback(&SecondLevelIDConfig{
+		HasIDAtSecondLevel: SectionsTestsMap{"users": {SectionTestIsNumeric, TestIsNumeric}},
+	})
+	for _, data := range dataProvider {
+		r := &http.Request{Method: http.MethodGet, URL: &url.URL{Path: data.Path}}
+		b := NewHTTPRequestWithDepth(SectionRequest, r, true, callback, true, data.Depth)
+		assert.Equal(t, data.Metric, b.Metric(), data.Path)
+		assert.Equal(t, data.Route, b.Route(), data.Path)
+	}
+}
@@ -11,26 +11,59 @@ type MetricOperation struct {
 
 	operations []string
 	Labels     map[string]string
+	// SampleRate is client-side sample rate for the operation, 0 means that section or client default is used
+	SampleRate float32
 }
 
-// NewMetricOperation  builds and returns new MetricOperation instance with defined label keys
+// NewMetricOperation  builds and returns new MetricOperation instance with defined label keys.
+// Operations list is padded with MetricEmptyPlaceholder up to MetricOperationsMaxLength, longer lists are kept
+// as is and are truncated to the bucket depth when metric name is built.
 func NewMetricOperation(operations ...string) *MetricOperation {
-	ops := []string{MetricEmptyPlaceholder, MetricEmptyPlaceholder, MetricEmptyPlaceholder}
-
-	opsLen := len(operations)
-	switch {
-	case opsLen >= MetricOperationsMaxLength:
-		ops[0] = operations[0]
-		ops[1] = operations[1]
-		ops[2] = operations[2]
-	case opsLen < 3:
-		for i := 0; i < opsLen; i++ {
-			ops[i] = operations[i]
-		}
+	ops := make([]string, MetricOperationsMaxLength, len(operations)+MetricOperationsMaxLength)
+	for i := range ops {
+		ops[i] = MetricEmptyPlaceholder
+	}
+	copy(ops, operations)
+	if len(operations) > MetricOperationsMaxLength {
+		ops = append(ops, operations[MetricOperationsMaxLength:]...)
 	}
+
 	return &MetricOperation{operations: ops}
 }
 
+// newMetricOperationWithDepth builds and returns new MetricOperation instance with exactly depth operations
+func newMetricOperationWithDepth(depth int, operations ...string) *MetricOperation {
+	return &MetricOperation{operations: operationsWithDepth(operations, depth)}
+}
+
+// Operations returns a copy of operations list, unset operations are filled with MetricEmptyPlaceholder
+func (m *MetricOperation) Operations() []string {
+	ops := make([]string, len(m.operations))
+	copy(ops, m.operations)
+	return ops
+}
+
+// OperationsWithDepth returns a copy of operations list padded with MetricEmptyPlaceholder or truncated
+// to the given depth, zero or negative depth means MetricOperationsMaxLength
+func (m *MetricOperation) OperationsWithDepth(depth int) []string {
+	return operationsWithDepth(m.operations, depth)
+}
+
+// operationsWithDepth returns a copy of operations padded with MetricEmptyPlaceholder or truncated to the given depth
+func operationsWithDepth(operations []string, depth int) []string {
+	if depth <= 0 {
+		depth = MetricOperationsMaxLength
+	}
+
+	ops := make([]string, depth)
+	for i := range ops {
+		ops[i] = MetricEmptyPlaceholder
+	}
+	copy(ops, operations)
+
+	return ops
+}
+
 // WithLabels adds label value to existing MetricOperation instance
 func (m *MetricOperation) WithLabels(labels map[string]string) *MetricOperation {
 
@@ -59,6 +92,29 @@ func (m *MetricOperation) WithLabels(labels map[string]string) *MetricOperation
 	return m
 }
 
+// Clone returns a copy of MetricOperation instance, so that clients could alter copy labels w/out affecting original
+func (m *MetricOperation) Clone() *MetricOperation {
+	m.Lock()
+	defer m.Unlock()
+
+	clone := &MetricOperation{operations: m.Operations(), SampleRate: m.SampleRate}
+	if m.Labels != nil {
+		clone.Labels = make(map[string]string, len(m.Labels))
+		for k, v := range m.Labels {
+			clone.Labels[k] = v
+		}
+	}
+
+	return clone
+}
+
+// WithSampleRate sets client-side sample rate for the operation, e.g. 0.1 to send only 10% of operation metrics,
+// it is applied by backends that send every metric over network, e.g. statsd
+func (m *MetricOperation) WithSampleRate(rate float32) *MetricOperation {
+	m.SampleRate = rate
+	return m
+}
+
 // Plain struct in an implementation of Bucket interface that produces metric names for given section and operation
 type Plain struct {
 	section   string
@@ -66,10 +122,17 @@ type Plain struct {
 	success   bool
 }
 
-// NewPlain builds and returns new Plain instance
+// NewPlain builds and returns new Plain instance with MetricOperationsMaxLength operations
 func NewPlain(section string, operation *MetricOperation, success, uniDecode bool) *Plain {
-	operationSanitized := make([]string, cap(operation.operations))
-	for k, v := range operation.operations {
+	return NewPlainWithDepth(section, operation, success, uniDecode, MetricOperationsMaxLength)
+}
+
+// NewPlainWithDepth builds and returns new Plain instance with operations padded with MetricEmptyPlaceholder
+// or truncated to the given depth, so that all metric names of a section have the same number of parts
+func NewPlainWithDepth(section string, operation *MetricOperation, success, uniDecode bool, depth int) *Plain {
+	operations := operation.OperationsWithDepth(depth)
+	operationSanitized := make([]string, len(operations))
+	for k, v := range operations {
 		operationSanitized[k] = SanitizeMetricName(v, uniDecode)
 	}
 	return &Plain{SanitizeMetricName(section, uniDecode), strings.Join(operationSanitized, "."), success}
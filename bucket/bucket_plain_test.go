@@ -121,3 +121,46 @@ func TestPlain_MetricTotalWithSuffix(t *testing.T) {
 		assert.Equal(t, data.Metric, b.MetricTotalWithSuffix())
 	}
 }
+
+func TestMetricOperation_Clone(t *testing.T) {
+	operation := NewMetricOperation("foo", "bar").WithLabels(map[string]string{"country": "de"}).WithSampleRate(0.5)
+
+	clone := operation.Clone()
+	clone.Labels["success"] = "true"
+
+	assert.Equal(t, operation.Operations(), clone.Operations())
+	assert.Equal(t, float32(0.5), clone.SampleRate)
+	assert.Equal(t, map[string]string{"country": "de"}, operation.Labels)
+	assert.Equal(t, map[string]string{"country": "de", "success": "true"}, clone.Labels)
+
+	assert.Nil(t, NewMetricOperation("foo").Clone().Labels)
+}
+
+func TestPlain_MetricWithDepth(t *testing.T) {
+	dataProvider := []struct {
+		Operation *MetricOperation
+		Depth     int
+		Metric    string
+	}{
+		{NewMetricOperation("bar", "baz", "qaz", "vaz", "taz"), 5, "foo.bar.baz.qaz.vaz.taz"},
+		{NewMetricOperation("bar", "baz", "qaz", "vaz", "taz"), 4, "foo.bar.baz.qaz.vaz"},
+		{NewMetricOperation("bar", "baz", "qaz", "vaz"), 5, "foo.bar.baz.qaz.vaz.-"},
+		{NewMetricOperation("bar"), 5, "foo.bar.-.-.-.-"},
+		{NewMetricOperation("bar", "baz"), 1, "foo.bar"},
+		{NewMetricOperation("bar", "baz", "qaz", "vaz"), 0, "foo.bar.baz.qaz"},
+	}
+
+	for _, data := range dataProvider {
+		b := NewPlainWithDepth("foo", data.Operation, true, true, data.Depth)
+		assert.Equal(t, data.Metric, b.Metric())
+	}
+}
+
+func TestMetricOperation_OperationsWithDepth(t *testing.T) {
+	operation := NewMetricOperation("bar", "baz", "qaz", "vaz")
+
+	assert.Equal(t, []string{"bar", "baz", "qaz", "vaz"}, operation.Operations())
+	assert.Equal(t, []string{"bar", "baz", "qaz"}, operation.OperationsWithDepth(0))
+	assert.Equal(t, []string{"bar", "baz", "qaz", "vaz", MetricEmptyPlaceholder}, operation.OperationsWithDepth(5))
+	assert.Equal(t, []string{"bar", MetricEmptyPlaceholder, MetricEmptyPlaceholder}, NewMetricOperation("bar").Operations())
+}
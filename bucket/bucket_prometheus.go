@@ -13,10 +13,16 @@ type Prometheus struct {
 	success   bool
 }
 
-// NewPrometheus builds and returns new Prometheus instance
+// NewPrometheus builds and returns new Prometheus instance with up to MetricOperationsMaxLength operations
 func NewPrometheus(section string, operation *MetricOperation, success, uniDecode bool) *Prometheus {
-	operationSanitized := make([]string, 0, len(operation.operations))
-	for _, v := range operation.operations {
+	return NewPrometheusWithDepth(section, operation, success, uniDecode, MetricOperationsMaxLength)
+}
+
+// NewPrometheusWithDepth builds and returns new Prometheus instance with operations truncated to the given depth
+func NewPrometheusWithDepth(section string, operation *MetricOperation, success, uniDecode bool, depth int) *Prometheus {
+	operations := operation.OperationsWithDepth(depth)
+	operationSanitized := make([]string, 0, len(operations))
+	for _, v := range operations {
 		sanitizedMetricName := sanitizeMetricName(v, uniDecode)
 		// prometheus doesn't allow _ in then end of metric name
 		if sanitizedMetricName != "" {
@@ -63,3 +63,11 @@ func TestPrometheus_MetricTotal(t *testing.T) {
 		assert.Equal(t, data.Metric, b.MetricTotal())
 	}
 }
+
+func TestPrometheus_MetricWithDepth(t *testing.T) {
+	operation := NewMetricOperation("bar", "baz", "qaz", "vaz", "taz")
+
+	assert.Equal(t, "foo_bar_baz_qaz", NewPrometheus("foo", operation, true, true).Metric())
+	assert.Equal(t, "foo_bar_baz_qaz_vaz", NewPrometheusWithDepth("foo", operation, true, true, 4).Metric())
+	assert.Equal(t, "foo_bar_baz_qaz_vaz_taz", NewPrometheusWithDepth("foo", operation, true, true, 6).Metric())
+}
@@ -1,30 +1,139 @@
 package bucket
 
-import "sync"
+import (
+	"container/list"
+	"sort"
+	"sync"
+	"time"
+)
 
+// AutoDiscoverStats is a snapshot of second level ID auto-discovery state
+type AutoDiscoverStats struct {
+	// TrackedSections is a number of first path sections currently tracked, including discovered ones
+	TrackedSections int
+	// Discovered is a sorted list of first path sections found to have IDs at the second level
+	Discovered []string
+	// Evicted is a number of least recently used first path sections evicted to keep tracked sections limit
+	Evicted uint64
+	// Expired is a number of first path sections that had their collected second path sections expired
+	Expired uint64
+	// Rejected is a number of first path sections that reached threshold after discovered sections limit was reached
+	Rejected uint64
+}
+
+// metricStorageEntry is a state of first path section that is not discovered to have IDs at the second level yet
+type metricStorageEntry struct {
+	firstSection string
+	values       map[string]struct{}
+	windowStart  time.Time
+}
+
+// metricStorage collects distinct second path sections for every first path section until threshold is reached.
+// Memory is bounded: every first path section keeps up to threshold values, number of first path sections
+// that are not discovered yet is limited with least recently used ones evicted, and values collected for them
+// are dropped once ttl window is over, so that rare paths do not add up to threshold over time.
+// Discovered first path sections are kept in a separate set without values and are never evicted or expired,
+// so that scans with many distinct first path sections do not bring IDs back to metric names. The set is limited
+// as well, once it is full new discoveries are rejected and their collected values are dropped.
 type metricStorage struct {
 	sync.Mutex
 
-	threshold uint
-	metrics   map[string]map[string]uint
+	threshold     uint
+	maxSections   uint
+	maxDiscovered uint
+	ttl           time.Duration
+	now           func() time.Time
+
+	sections   map[string]*list.Element
+	lru        *list.List
+	discovered map[string]struct{}
+	evicted    uint64
+	expired    uint64
+	rejected   uint64
 }
 
-func newMetricStorage(threshold uint) *metricStorage {
-	return &metricStorage{threshold: threshold, metrics: make(map[string]map[string]uint)}
+func newMetricStorage(threshold, maxSections, maxDiscovered uint, ttl time.Duration) *metricStorage {
+	return &metricStorage{
+		threshold:     threshold,
+		maxSections:   maxSections,
+		maxDiscovered: maxDiscovered,
+		ttl:           ttl,
+		now:           time.Now,
+		sections:      make(map[string]*list.Element),
+		lru:           list.New(),
+		discovered:    make(map[string]struct{}),
+	}
 }
 
 func (s *metricStorage) LooksLikeID(firstSection, secondSection string) bool {
 	s.Lock()
 	defer s.Unlock()
 
-	if _, ok := s.metrics[firstSection]; !ok {
-		s.metrics[firstSection] = make(map[string]uint, s.threshold)
+	if _, ok := s.discovered[firstSection]; ok {
+		return true
+	}
+
+	element := s.getElement(firstSection)
+	entry := element.Value.(*metricStorageEntry)
+	entry.values[secondSection] = struct{}{}
+	if uint(len(entry.values)) < s.threshold {
+		return false
+	}
+
+	// avoid storing values that are not needed anymore to avoid memory loss
+	s.lru.Remove(element)
+	delete(s.sections, firstSection)
+
+	if s.maxDiscovered > 0 && uint(len(s.discovered)) >= s.maxDiscovered {
+		s.rejected++
+		return false
+	}
+	s.discovered[firstSection] = struct{}{}
+
+	return true
+}
+
+// getElement returns first path section state list element marking it as the most recently used one,
+// creates new one evicting the least recently used if needed and resets expired one
+func (s *metricStorage) getElement(firstSection string) *list.Element {
+	now := s.now()
+
+	if element, ok := s.sections[firstSection]; ok {
+		s.lru.MoveToFront(element)
+
+		entry := element.Value.(*metricStorageEntry)
+		if s.ttl > 0 && now.Sub(entry.windowStart) >= s.ttl {
+			entry.values = make(map[string]struct{})
+			entry.windowStart = now
+			s.expired++
+		}
+
+		return element
 	}
 
-	// avoid storing all values to avoid memory loss
-	if uint(len(s.metrics[firstSection])) < s.threshold {
-		s.metrics[firstSection][secondSection]++
+	element := s.lru.PushFront(&metricStorageEntry{firstSection: firstSection, values: make(map[string]struct{}), windowStart: now})
+	s.sections[firstSection] = element
+
+	if s.maxSections > 0 && uint(s.lru.Len()) > s.maxSections {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.sections, oldest.Value.(*metricStorageEntry).firstSection)
+		s.evicted++
+	}
+
+	return element
+}
+
+// Stats returns auto-discovery state snapshot
+func (s *metricStorage) Stats() AutoDiscoverStats {
+	s.Lock()
+	defer s.Unlock()
+
+	stats := AutoDiscoverStats{TrackedSections: s.lru.Len() + len(s.discovered), Evicted: s.evicted, Expired: s.expired, Rejected: s.rejected}
+	for firstSection := range s.discovered {
+		stats.Discovered = append(stats.Discovered, firstSection)
 	}
+	sort.Strings(stats.Discovered)
 
-	return uint(len(s.metrics[firstSection])) >= s.threshold
+	return stats
 }
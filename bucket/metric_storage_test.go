@@ -2,6 +2,8 @@ package bucket
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -9,15 +11,108 @@ import (
 )
 
 func TestMetricStorage_LooksLikeID(t *testing.T) {
-	storage := newMetricStorage(25)
+	storage := newMetricStorage(25, DefaultAutoDiscoverMaxSections, DefaultAutoDiscoverMaxDiscovered, 0)
 	firstSection := time.Now().Format(time.RFC3339Nano)
 
 	for i := uint(0); i < storage.threshold-1; i++ {
 		assert.False(t, storage.LooksLikeID(firstSection, fmt.Sprint(i)))
 	}
+	assert.Equal(t, int(storage.threshold-1), len(storage.sections[firstSection].Value.(*metricStorageEntry).values))
 
 	assert.True(t, storage.LooksLikeID(firstSection, fmt.Sprint(storage.threshold+1)))
 	assert.True(t, storage.LooksLikeID(firstSection, fmt.Sprint(storage.threshold+1)))
 
-	assert.Equal(t, storage.threshold, uint(len(storage.metrics[firstSection])))
+	// values are not stored once section is discovered
+	assert.NotContains(t, storage.sections, firstSection)
+	assert.Equal(t, 0, storage.lru.Len())
+	assert.Contains(t, storage.discovered, firstSection)
+}
+
+func TestMetricStorage_MaxSections(t *testing.T) {
+	storage := newMetricStorage(2, 3, DefaultAutoDiscoverMaxDiscovered, 0)
+
+	assert.False(t, storage.LooksLikeID("users", "1"))
+	assert.True(t, storage.LooksLikeID("users", "2"))
+
+	// scanner garbage paths do not grow the storage over the limit
+	for i := 0; i < 100; i++ {
+		assert.False(t, storage.LooksLikeID(fmt.Sprintf("scan%d", i), "x"))
+		// recently used sections are kept
+		assert.True(t, storage.LooksLikeID("users", "3"))
+	}
+
+	// discovered section is not counted against the limit
+	assert.Equal(t, 3, storage.lru.Len())
+	assert.Equal(t, 3, len(storage.sections))
+	assert.Equal(t, AutoDiscoverStats{TrackedSections: 4, Discovered: []string{"users"}, Evicted: 97}, storage.Stats())
+}
+
+func TestMetricStorage_DiscoveredNotEvicted(t *testing.T) {
+	storage := newMetricStorage(2, 2, 3, 0)
+
+	assert.False(t, storage.LooksLikeID("users", "1"))
+	assert.True(t, storage.LooksLikeID("users", "2"))
+
+	// scan with more distinct first path sections than the limit, discovered section is never used during it
+	for i := 0; i < 100; i++ {
+		assert.False(t, storage.LooksLikeID(fmt.Sprintf("scan%d", i), "x"))
+	}
+
+	assert.True(t, storage.LooksLikeID("users", "3"))
+	assert.Equal(t, AutoDiscoverStats{TrackedSections: 3, Discovered: []string{"users"}, Evicted: 98}, storage.Stats())
+
+	// scan with threshold distinct second path sections under every first path section fills discovered sections
+	// up to the limit, the rest are rejected and do not grow memory
+	for i := 0; i < 100; i++ {
+		storage.LooksLikeID(fmt.Sprintf("scan%d", i), "a")
+		storage.LooksLikeID(fmt.Sprintf("scan%d", i), "b")
+	}
+
+	assert.True(t, storage.LooksLikeID("users", "4"))
+	assert.True(t, storage.LooksLikeID("scan0", "c"))
+	assert.True(t, storage.LooksLikeID("scan1", "c"))
+	assert.False(t, storage.LooksLikeID("scan2", "c"))
+
+	stats := storage.Stats()
+	assert.Equal(t, []string{"scan0", "scan1", "users"}, stats.Discovered)
+	assert.Equal(t, uint64(98), stats.Rejected)
+	// discovered sections limit plus not yet discovered sections limit
+	assert.Equal(t, 5, stats.TrackedSections)
+}
+
+func TestMetricStorage_TTL(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	storage := newMetricStorage(3, DefaultAutoDiscoverMaxSections, DefaultAutoDiscoverMaxDiscovered, time.Hour)
+	storage.now = func() time.Time {
+		return now
+	}
+
+	assert.False(t, storage.LooksLikeID("products", "new"))
+	assert.False(t, storage.LooksLikeID("products", "list"))
+
+	// values collected in the previous window are forgotten
+	now = now.Add(time.Hour)
+	assert.False(t, storage.LooksLikeID("products", "search"))
+	assert.False(t, storage.LooksLikeID("products", "new"))
+
+	now = now.Add(59 * time.Minute)
+	assert.True(t, storage.LooksLikeID("products", "list"))
+
+	// discovered sections do not expire
+	now = now.Add(24 * time.Hour)
+	assert.True(t, storage.LooksLikeID("products", "123"))
+
+	assert.Equal(t, AutoDiscoverStats{TrackedSections: 1, Discovered: []string{"products"}, Expired: 1}, storage.Stats())
+}
+
+func TestSecondLevelIDConfig_AutoDiscoverStats(t *testing.T) {
+	config := &SecondLevelIDConfig{AutoDiscoverThreshold: 2, AutoDiscoverMaxSections: 2, AutoDiscoverWhiteList: []string{"search"}}
+	assert.Equal(t, AutoDiscoverStats{}, config.AutoDiscoverStats())
+
+	callback := NewHasIDAtSecondLevelCallback(config)
+	for _, path := range []string{"/users/1", "/users/2", "/search/foo", "/search/bar", "/wp-admin/x", "/.env/y", "/phpmyadmin/z", "/users/3"} {
+		BuildHTTPRequestMetricOperation(httptest.NewRequest(http.MethodGet, path, nil), callback)
+	}
+
+	assert.Equal(t, AutoDiscoverStats{TrackedSections: 3, Discovered: []string{"users"}, Evicted: 1}, config.AutoDiscoverStats())
 }
@@ -0,0 +1,169 @@
+package bucket
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const (
+	pathRuleDelimiter = "->"
+	pathRuleComment   = "#"
+	pathRuleRegexp    = "^"
+)
+
+// ErrInvalidPathRule error indicates that path rule has invalid format, pattern or replacement
+var ErrInvalidPathRule = errors.New("invalid path rule")
+
+// PathRule is a rule that normalises HTTP Request path matching pattern to metric operation path levels
+type PathRule struct {
+	Pattern     string
+	Replacement string
+
+	re *regexp.Regexp
+}
+
+// NewPathRule builds and returns new PathRule instance. Pattern is either regular expression if it starts with "^",
+// e.g. "^/api/v\d+/orders/(\d+)$", or glob otherwise, where "*" matches any characters within path segment,
+// "**" matches any number of path segments and ":name" matches whole path segment captured with the given name,
+// e.g. "/v*/products/:sku/**". Glob always matches the whole path, trailing slash is ignored.
+// Replacement is a list of dot-separated path levels, e.g. "products.-id-", levels that do not fit
+// operation depth are skipped, levels may refer to pattern captures as "$1", "$name" or "${name}", e.g. "products.$sku".
+func NewPathRule(pattern, replacement string) (*PathRule, error) {
+	pattern = strings.TrimSpace(pattern)
+	replacement = strings.TrimSpace(replacement)
+	if pattern == "" || replacement == "" {
+		return nil, ErrInvalidPathRule
+	}
+
+	expr := pattern
+	if !strings.HasPrefix(pattern, pathRuleRegexp) {
+		expr = globToRegexp(pattern)
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, ErrInvalidPathRule
+	}
+
+	return &PathRule{Pattern: pattern, Replacement: replacement, re: re}, nil
+}
+
+// globToRegexp converts path glob pattern to regular expression that matches the whole path
+func globToRegexp(pattern string) string {
+	var segments []string
+	for _, segment := range strings.Split(trimPath(pattern), "/") {
+		switch {
+		case segment == "**":
+			segments = append(segments, ".*")
+		case strings.HasPrefix(segment, ":") && len(segment) > 1:
+			segments = append(segments, "(?P<"+segment[1:]+">[^/]+)")
+		default:
+			parts := strings.Split(segment, "*")
+			for i := range parts {
+				parts[i] = regexp.QuoteMeta(parts[i])
+			}
+			segments = append(segments, strings.Join(parts, "[^/]*"))
+		}
+	}
+
+	// "/**" matches the parent path as well, e.g. "/products/**" matches "/products"
+	return "^" + strings.Replace(strings.Join(segments, "/"), "/.*", "(?:/.*)?", -1) + "$"
+}
+
+// trimPath removes trailing slash from path except root one
+func trimPath(path string) string {
+	if len(path) > 1 {
+		return strings.TrimSuffix(path, "/")
+	}
+
+	return path
+}
+
+// Match checks if path matches rule pattern and returns path levels built from replacement
+func (r *PathRule) Match(path string) ([]string, bool) {
+	path = trimPath(path)
+
+	submatches := r.re.FindStringSubmatchIndex(path)
+	if submatches == nil {
+		return nil, false
+	}
+
+	replacement := r.re.ExpandString(nil, r.Replacement, path, submatches)
+	return strings.Split(string(replacement), "."), true
+}
+
+// ParsePathRules parses string into the ordered list of path rules.
+// In most cases string comes as config to the application e.g. from env or file.
+// Every rule is set on its own line in the "<pattern> -> <replacement>" format,
+// empty lines and lines starting with "#" are skipped, e.g.:
+//
+//	# IDs at the third and fourth levels
+//	/v*/products/:sku/** -> products.-id-
+//	/api/users/*/orders/:id -> orders.-id-
+//	^/legacy/(\w+)/\d+$ -> legacy.$1
+func ParsePathRules(s string) ([]*PathRule, error) {
+	var rules []*PathRule
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, pathRuleComment) {
+			continue
+		}
+
+		i := strings.LastIndex(line, pathRuleDelimiter)
+		if i < 0 {
+			return nil, ErrInvalidPathRule
+		}
+
+		rule, err := NewPathRule(line[:i], line[i+len(pathRuleDelimiter):])
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// LoadPathRules reads file with the given name and parses its contents into the ordered list of path rules,
+// see ParsePathRules for format
+func LoadPathRules(filename string) ([]*PathRule, error) {
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParsePathRules(string(contents))
+}
+
+// NewPathRulesCallback returns HTTPMetricNameAlterCallback implementation that builds metric operation path levels
+// from the first rule that matches HTTP Request path, e.g. "GET /v2/products/abc-123/reviews" request matched by
+// "/v*/products/:sku/** -> products.-id-" rule gets "get.products.-id-" operation, so IDs at any path level
+// can be stripped. Fallback callback, if set, is called for requests with no rule matched.
+func NewPathRulesCallback(fallback HTTPMetricNameAlterCallback, rules ...*PathRule) HTTPMetricNameAlterCallback {
+	return func(operation *MetricOperation, r *http.Request) *MetricOperation {
+		for _, rule := range rules {
+			levels, ok := rule.Match(r.URL.Path)
+			if !ok {
+				continue
+			}
+
+			for i := 1; i < len(operation.operations); i++ {
+				operation.operations[i] = MetricEmptyPlaceholder
+				if i-1 < len(levels) && levels[i-1] != "" {
+					operation.operations[i] = levels[i-1]
+				}
+			}
+
+			return operation
+		}
+
+		if fallback != nil {
+			return fallback(operation, r)
+		}
+
+		return operation
+	}
+}
@@ -0,0 +1,136 @@
+package bucket
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPathRule(t *testing.T) {
+	dataProvider := []struct {
+		Pattern     string
+		Replacement string
+		Path        string
+		Levels      []string
+		Matched     bool
+	}{
+		{"/v*/products/:sku/**", "products.-id-", "/v2/products/abc-123/reviews/5", []string{"products", "-id-"}, true},
+		{"/v*/products/:sku/**", "products.-id-", "/v2/products/abc-123", []string{"products", "-id-"}, true},
+		{"/v*/products/:sku/**", "products.-id-", "/v2/products/abc-123/", []string{"products", "-id-"}, true},
+		{"/v*/products/:sku/**", "products.-id-", "/v2/products", nil, false},
+		{"/v*/products/:sku/**", "products.-id-", "/api/products/abc-123", nil, false},
+		{"/api/users/*/orders/:id", "orders.-id-", "/api/users/42/orders/17", []string{"orders", "-id-"}, true},
+		{"/api/users/*/orders/:id", "orders.-id-", "/api/users/42/orders/17/items", nil, false},
+		{"/api/*/:action", "api.$action", "/api/users/search", []string{"api", "search"}, true},
+		{"/api/*/:action", "${action}", "/api/users/search", []string{"search"}, true},
+		{"/files/**", "files", "/files/a/b/c.txt", []string{"files"}, true},
+		{"/**", "other", "/", []string{"other"}, true},
+		{"/api.v1/*", "api", "/apixv1/foo", nil, false},
+		{`^/legacy/(\w+)/\d+$`, "legacy.$1", "/legacy/recipes/123", []string{"legacy", "recipes"}, true},
+		{`^/legacy/(\w+)/\d+$`, "legacy.$1", "/legacy/recipes/abc", nil, false},
+	}
+
+	for _, data := range dataProvider {
+		t.Run(data.Pattern+" "+data.Path, func(t *testing.T) {
+			rule, err := NewPathRule(data.Pattern, data.Replacement)
+			require.NoError(t, err)
+
+			levels, ok := rule.Match(data.Path)
+			assert.Equal(t, data.Matched, ok)
+			assert.Equal(t, data.Levels, levels)
+		})
+	}
+}
+
+func TestNewPathRule_Invalid(t *testing.T) {
+	for _, data := range [][2]string{
+		{"", "products"},
+		{"/products", ""},
+		{"^/products/(", "products"},
+		{"/products/:sku-id", "products"},
+	} {
+		_, err := NewPathRule(data[0], data[1])
+		assert.Equal(t, ErrInvalidPathRule, err, data[0])
+	}
+}
+
+func TestParsePathRules(t *testing.T) {
+	rules, err := ParsePathRules(`
+		# IDs at the third and fourth levels
+		/v*/products/:sku/** -> products.-id-
+		/api/users/*/orders/:id->orders.-id-
+
+		^/legacy/(\w+)/\d+$ -> legacy.$1
+	`)
+	require.NoError(t, err)
+	require.Len(t, rules, 3)
+	assert.Equal(t, "/v*/products/:sku/**", rules[0].Pattern)
+	assert.Equal(t, "products.-id-", rules[0].Replacement)
+	assert.Equal(t, "/api/users/*/orders/:id", rules[1].Pattern)
+	assert.Equal(t, `^/legacy/(\w+)/\d+$`, rules[2].Pattern)
+
+	_, err = ParsePathRules("/products products")
+	assert.Equal(t, ErrInvalidPathRule, err)
+
+	rules, err = ParsePathRules("")
+	assert.NoError(t, err)
+	assert.Empty(t, rules)
+}
+
+func TestLoadPathRules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stats-go")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "rules.txt")
+	require.NoError(t, ioutil.WriteFile(filename, []byte("/v*/products/:sku/** -> products.-id-\n"), 0600))
+
+	rules, err := LoadPathRules(filename)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, "/v*/products/:sku/**", rules[0].Pattern)
+
+	_, err = LoadPathRules(filepath.Join(dir, "missing.txt"))
+	assert.Error(t, err)
+}
+
+func TestNewPathRulesCallback(t *testing.T) {
+	rules, err := ParsePathRules(`
+		/v*/products/:sku/** -> products.-id-
+		/api/users/*/orders/:id -> orders.-id-
+		/api/** -> api
+	`)
+	require.NoError(t, err)
+
+	fallback := NewHasIDAtSecondLevelCallback(&SecondLevelIDConfig{
+		HasIDAtSecondLevel: SectionsTestsMap{"users": {SectionTestTrue, TestAlwaysTrue}},
+	})
+	callback := NewPathRulesCallback(fallback, rules...)
+
+	dataProvider := []struct {
+		Method string
+		Path   string
+		Metric string
+	}{
+		{http.MethodGet, "/v1/products/abc-123/reviews", "request.get.products.-id-"},
+		{http.MethodPut, "/api/users/42/orders/17", "request.put.orders.-id-"},
+		// rules are checked in the given order
+		{http.MethodGet, "/api/users/42", "request.get.api.-"},
+		// fallback is used if no rule matched
+		{http.MethodGet, "/users/42", "request.get.users.-id-"},
+		{http.MethodGet, "/recipes/42", "request.get.recipes.42"},
+	}
+
+	for _, data := range dataProvider {
+		t.Run(data.Path, func(t *testing.T) {
+			r := httptest.NewRequest(data.Method, data.Path, nil)
+			assert.Equal(t, data.Metric, NewHTTPRequest(SectionRequest, r, true, callback, false).Metric())
+		})
+	}
+}
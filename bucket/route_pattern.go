@@ -0,0 +1,103 @@
+package bucket
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// RoutePatternExtractor is a type for function that returns route pattern matched by router for HTTP Request,
+// e.g. "/users/{id}", or empty string if request was not matched by the router
+type RoutePatternExtractor func(r *http.Request) string
+
+// routePatternProvider is implemented by router context values that know matched route pattern, e.g. chi.Context
+type routePatternProvider interface {
+	RoutePattern() string
+}
+
+// NewContextRoutePatternExtractor returns RoutePatternExtractor implementation that gets matched route pattern
+// from request context value with the given key, value must have "RoutePattern() string" method,
+// e.g. for chi router: bucket.NewContextRoutePatternExtractor(chi.RouteCtxKey)
+func NewContextRoutePatternExtractor(key interface{}) RoutePatternExtractor {
+	return func(r *http.Request) string {
+		return contextRoutePattern(r.Context(), key)
+	}
+}
+
+func contextRoutePattern(ctx context.Context, key interface{}) string {
+	if provider, ok := ctx.Value(key).(routePatternProvider); ok {
+		return provider.RoutePattern()
+	}
+
+	return ""
+}
+
+// NewRoutePatternCallback returns HTTPMetricNameAlterCallback implementation that builds metric operation path levels
+// from route pattern matched by router instead of raw request path, so IDs do not leak into metric names,
+// e.g. "GET /users/123/orders" request matched by "/users/{id}/orders" route gets "get.users.{id}" operation.
+// Extractors are called in the given order until one of them returns non-empty pattern, ServeMuxPatternExtractor
+// is used if no extractors are given. Fallback callback, if set, is called for requests with no pattern matched.
+//
+// Route pattern is available only after request is routed, so stats middleware must either wrap the router
+// that sets pattern to the request it was called with, e.g. http.ServeMux, or be added as router middleware,
+// e.g. for gorilla/mux and chi.
+func NewRoutePatternCallback(fallback HTTPMetricNameAlterCallback, extractors ...RoutePatternExtractor) HTTPMetricNameAlterCallback {
+	if len(extractors) == 0 {
+		extractors = []RoutePatternExtractor{ServeMuxPatternExtractor}
+	}
+
+	return func(operation *MetricOperation, r *http.Request) *MetricOperation {
+		for _, extractor := range extractors {
+			pattern := extractor(r)
+			if pattern == "" {
+				continue
+			}
+
+			levels := routePatternLevels(pattern)
+			for i := 1; i < len(operation.operations); i++ {
+				operation.operations[i] = MetricEmptyPlaceholder
+				if i-1 < len(levels) {
+					operation.operations[i] = levels[i-1]
+				}
+			}
+
+			return operation
+		}
+
+		if fallback != nil {
+			return fallback(operation, r)
+		}
+
+		return operation
+	}
+}
+
+// routePatternLevels splits route pattern into path levels with normalised wildcards, the following
+// pattern formats are supported: "[METHOD ][HOST]/path/{id}/{rest...}/{$}" for http.ServeMux,
+// "/path/{id:[0-9]+}" for gorilla/mux and chi, and "/path/*" for chi
+func routePatternLevels(pattern string) []string {
+	// http.ServeMux pattern may start with method and host, path always starts with slash
+	if i := strings.Index(pattern, "/"); i > 0 {
+		pattern = pattern[i:]
+	}
+
+	var levels []string
+	for _, level := range strings.Split(pattern, "/") {
+		if level == "" || level == "{$}" {
+			continue
+		}
+
+		if strings.HasPrefix(level, "{") && strings.HasSuffix(level, "}") {
+			name := level[1 : len(level)-1]
+			// gorilla/mux and chi variables may have regexp after colon
+			if i := strings.Index(name, ":"); i >= 0 {
+				name = name[:i]
+			}
+			level = "{" + strings.TrimSuffix(name, "...") + "}"
+		}
+
+		levels = append(levels, level)
+	}
+
+	return levels
+}
@@ -0,0 +1,12 @@
+//go:build !go1.22
+// +build !go1.22
+
+package bucket
+
+import "net/http"
+
+// ServeMuxPatternExtractor is RoutePatternExtractor implementation that returns pattern
+// matched by http.ServeMux, http.Request has no matched pattern before Go 1.22, so empty string is always returned
+func ServeMuxPatternExtractor(r *http.Request) string {
+	return ""
+}
@@ -0,0 +1,13 @@
+//go:build go1.22
+// +build go1.22
+
+package bucket
+
+import "net/http"
+
+// ServeMuxPatternExtractor is RoutePatternExtractor implementation that returns pattern
+// matched by http.ServeMux, available since Go 1.22. Note that http.ServeMux supports patterns with methods and
+// wildcards only for modules with go version 1.22 or newer or with "httpmuxgo121=0" GODEBUG setting.
+func ServeMuxPatternExtractor(r *http.Request) string {
+	return r.Pattern
+}
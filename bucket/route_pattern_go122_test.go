@@ -0,0 +1,33 @@
+//go:build go1.22
+// +build go1.22
+
+// module go version is older than 1.22, so http.ServeMux patterns have to be enabled explicitly
+//go:debug httpmuxgo121=0
+
+package bucket
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeMuxPatternExtractor(t *testing.T) {
+	var operation *MetricOperation
+
+	callback := NewRoutePatternCallback(nil)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}/orders", func(w http.ResponseWriter, r *http.Request) {})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r)
+		operation = BuildHTTPRequestMetricOperation(r, callback)
+	})
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/123/orders", nil))
+	assert.Equal(t, []string{"get", "users", "{id}"}, operation.Operations())
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/123", nil))
+	assert.Equal(t, []string{"get", "orders", "123"}, operation.Operations())
+}
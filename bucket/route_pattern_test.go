@@ -0,0 +1,90 @@
+package bucket
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type routeContextMock struct {
+	pattern string
+}
+
+func (m *routeContextMock) RoutePattern() string {
+	return m.pattern
+}
+
+type routeContextKey struct{}
+
+func TestRoutePatternLevels(t *testing.T) {
+	dataProvider := []struct {
+		Pattern string
+		Levels  []string
+	}{
+		{"/", nil},
+		{"/users", []string{"users"}},
+		{"/users/{id}/orders", []string{"users", "{id}", "orders"}},
+		{"GET /users/{id}", []string{"users", "{id}"}},
+		{"GET example.com/users/{id}/{$}", []string{"users", "{id}"}},
+		{"/files/{path...}", []string{"files", "{path}"}},
+		{"/users/{id:[0-9]+}", []string{"users", "{id}"}},
+		{"/static/*", []string{"static", "*"}},
+	}
+
+	for _, data := range dataProvider {
+		t.Run(data.Pattern, func(t *testing.T) {
+			assert.Equal(t, data.Levels, routePatternLevels(data.Pattern))
+		})
+	}
+}
+
+func TestNewRoutePatternCallback(t *testing.T) {
+	extractor := func(r *http.Request) string {
+		return r.Header.Get("X-Route")
+	}
+	fallbackCalled := 0
+	fallback := func(operation *MetricOperation, r *http.Request) *MetricOperation {
+		fallbackCalled++
+		operation.operations[2] = MetricIDPlaceholder
+		return operation
+	}
+	callback := NewRoutePatternCallback(fallback, extractor)
+
+	r := httptest.NewRequest(http.MethodGet, "/users/123/orders", nil)
+	r.Header.Set("X-Route", "/users/{id}/orders")
+	assert.Equal(t, []string{"get", "users", "{id}"}, BuildHTTPRequestMetricOperation(r, callback).Operations())
+
+	r.Header.Set("X-Route", "/")
+	assert.Equal(t, []string{"get", MetricEmptyPlaceholder, MetricEmptyPlaceholder}, BuildHTTPRequestMetricOperation(r, callback).Operations())
+	assert.Equal(t, 0, fallbackCalled)
+
+	r.Header.Del("X-Route")
+	assert.Equal(t, []string{"get", "users", MetricIDPlaceholder}, BuildHTTPRequestMetricOperation(r, callback).Operations())
+	assert.Equal(t, 1, fallbackCalled)
+
+	b := NewHTTPRequest(SectionRequest, r, true, NewRoutePatternCallback(nil, extractor), false)
+	assert.Equal(t, "request.get.users.123", b.Metric())
+
+	r.Header.Set("X-Route", "/users/{id}/orders")
+	b = NewHTTPRequest(SectionRequest, r, true, NewRoutePatternCallback(nil, extractor), false)
+	assert.Equal(t, "request.get.users.{id}", b.Metric())
+	assert.Equal(t, "/users/{id}", b.Route())
+}
+
+func TestNewContextRoutePatternExtractor(t *testing.T) {
+	extractor := NewContextRoutePatternExtractor(routeContextKey{})
+
+	r := httptest.NewRequest(http.MethodGet, "/users/123", nil)
+	assert.Equal(t, "", extractor(r))
+
+	rctx := &routeContextMock{}
+	r = r.WithContext(context.WithValue(r.Context(), routeContextKey{}, rctx))
+	assert.Equal(t, "", extractor(r))
+
+	// pattern is filled by router after request context is created
+	rctx.pattern = "/users/{id}"
+	assert.Equal(t, "/users/{id}", extractor(r))
+}
@@ -1,13 +1,17 @@
 package bucket
 
 import (
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
+	"unicode"
 
 	"github.com/hellofresh/stats-go/log"
 )
@@ -22,6 +26,25 @@ const (
 	SectionTestIsNumeric = "numeric"
 	// SectionTestIsNotEmpty is a name for "stats.TestIsNotEmpty" test callback function
 	SectionTestIsNotEmpty = "not_empty"
+	// SectionTestIsUUID is a name for "stats.TestIsUUID" test callback function
+	SectionTestIsUUID = "uuid"
+	// SectionTestIsHex is a name for "stats.TestIsHex" test callback function
+	SectionTestIsHex = "hex"
+	// SectionTestIsULID is a name for "stats.TestIsULID" test callback function
+	SectionTestIsULID = "ulid"
+	// SectionTestIsDate is a name for "stats.TestIsDate" test callback function
+	SectionTestIsDate = "date"
+	// SectionTestIsBase64 is a name for "stats.TestIsBase64" test callback function
+	SectionTestIsBase64 = "base64"
+	// SectionTestIsEmail is a name for "stats.TestIsEmail" test callback function
+	SectionTestIsEmail = "email"
+	// SectionTestIsID is a name for "stats.TestIsID" test callback function
+	SectionTestIsID = "id"
+
+	// hexMinLength is minimal length of hex section, so that short words like "cafe" or "added" are not treated as IDs
+	hexMinLength = 16
+	// base64MinLength is minimal length of base64 section, so that regular words and slugs are not treated as IDs
+	base64MinLength = 20
 )
 
 var (
@@ -77,30 +100,145 @@ func TestIsNotEmpty(s PathSection) bool {
 	return string(s) != MetricEmptyPlaceholder
 }
 
+var (
+	uuidRegexp  = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hexRegexp   = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+	ulidRegexp  = regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Za-hjkmnp-tv-z]{25}$`)
+	emailRegexp = regexp.MustCompile(`^[^@\s/]+@[^@\s/]+\.[^@\s/.]+$`)
+
+	dateLayouts = []string{"2006-01-02", time.RFC3339, time.RFC3339Nano}
+)
+
+// TestIsUUID section test callback function that gives true result if section is UUID in canonical form,
+// e.g. "123e4567-e89b-12d3-a456-426614174000"
+func TestIsUUID(s PathSection) bool {
+	return uuidRegexp.MatchString(string(s))
+}
+
+// TestIsHex section test callback function that gives true result if section is hex string of at least 16 characters,
+// e.g. md5 or sha1 hash or MongoDB ObjectID
+func TestIsHex(s PathSection) bool {
+	return len(s) >= hexMinLength && hexRegexp.MatchString(string(s))
+}
+
+// TestIsULID section test callback function that gives true result if section is ULID,
+// e.g. "01ARZ3NDEKTSV4RRFFQ69G5FAV"
+func TestIsULID(s PathSection) bool {
+	return ulidRegexp.MatchString(string(s))
+}
+
+// TestIsDate section test callback function that gives true result if section is date in "2006-01-02" form
+// or RFC 3339 timestamp
+func TestIsDate(s PathSection) bool {
+	for _, layout := range dateLayouts {
+		if _, err := time.Parse(layout, string(s)); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TestIsBase64 section test callback function that gives true result if section is standard or URL-safe base64
+// encoded string of at least 20 characters that has digits and either padding, "+" or "/" characters
+// or both upper and lower case letters, e.g. opaque token, so that lower case slugs are not treated as IDs
+func TestIsBase64(s PathSection) bool {
+	str := string(s)
+	if len(str) < base64MinLength || !strings.ContainsAny(str, "0123456789") {
+		return false
+	}
+	if !strings.ContainsAny(str, "=+/") && (strings.IndexFunc(str, unicode.IsUpper) < 0 || strings.IndexFunc(str, unicode.IsLower) < 0) {
+		return false
+	}
+
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if _, err := encoding.DecodeString(str); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TestIsEmail section test callback function that gives true result if section looks like email address
+func TestIsEmail(s PathSection) bool {
+	return emailRegexp.MatchString(string(s))
+}
+
+// TestIsID section test callback function that gives true result if section passes any of numeric, uuid, hex,
+// ulid, date, base64 or email tests
+func TestIsID(s PathSection) bool {
+	return TestIsNumeric(s) || TestIsUUID(s) || TestIsHex(s) || TestIsULID(s) ||
+		TestIsDate(s) || TestIsBase64(s) || TestIsEmail(s)
+}
+
 var (
 	sectionsTestSync     sync.Mutex
 	sectionsTestRegistry = map[string]SectionTestCallback{
 		SectionTestTrue:       TestAlwaysTrue,
 		SectionTestIsNumeric:  TestIsNumeric,
 		SectionTestIsNotEmpty: TestIsNotEmpty,
+		SectionTestIsUUID:     TestIsUUID,
+		SectionTestIsHex:      TestIsHex,
+		SectionTestIsULID:     TestIsULID,
+		SectionTestIsDate:     TestIsDate,
+		SectionTestIsBase64:   TestIsBase64,
+		SectionTestIsEmail:    TestIsEmail,
+		SectionTestIsID:       TestIsID,
 	}
 )
 
-// SecondLevelIDConfig configuration struct for second level ID callback
+const (
+	// DefaultAutoDiscoverMaxSections is a default number of first path sections second level ID auto-discovery tracks
+	DefaultAutoDiscoverMaxSections = 1000
+	// DefaultAutoDiscoverMaxDiscovered is a default number of first path sections second level ID auto-discovery
+	// keeps as discovered to have IDs at the second level
+	DefaultAutoDiscoverMaxDiscovered = 1000
+)
+
+// SecondLevelIDConfig configuration struct for second level ID callback.
+// Auto-discovery treats second path level as ID once AutoDiscoverThreshold distinct values are seen for the same
+// first path level. It tracks up to AutoDiscoverMaxSections not yet discovered first path levels,
+// DefaultAutoDiscoverMaxSections if not set, least recently used ones are forgotten when the limit is reached,
+// e.g. during vulnerability scan, while discovered ones are always kept. Up to AutoDiscoverMaxDiscovered first path
+// levels are discovered, DefaultAutoDiscoverMaxDiscovered if not set, new discoveries are rejected after that.
+// If AutoDiscoverTTL is set, values collected for first path level are forgotten if threshold was not reached
+// within TTL window, so that rare legitimate paths do not add up to threshold over time.
 type SecondLevelIDConfig struct {
-	HasIDAtSecondLevel    SectionsTestsMap
-	AutoDiscoverThreshold uint
-	AutoDiscoverWhiteList []string
+	HasIDAtSecondLevel        SectionsTestsMap
+	AutoDiscoverThreshold     uint
+	AutoDiscoverWhiteList     []string
+	AutoDiscoverMaxSections   uint
+	AutoDiscoverMaxDiscovered uint
+	AutoDiscoverTTL           time.Duration
 
 	autoDiscoverStorage  *metricStorage
 	autoDiscoverWhiteMap map[string]bool
 }
 
+// AutoDiscoverStats returns second level ID auto-discovery state snapshot, e.g. to expose it for debugging,
+// empty stats are returned if auto-discovery is disabled or callback was not created for the config yet
+func (c *SecondLevelIDConfig) AutoDiscoverStats() AutoDiscoverStats {
+	if c.autoDiscoverStorage == nil {
+		return AutoDiscoverStats{}
+	}
+
+	return c.autoDiscoverStorage.Stats()
+}
+
 // NewHasIDAtSecondLevelCallback returns HttpMetricNameAlterCallback implementation that checks for IDs
 // on the second level of HTTP Request path
 func NewHasIDAtSecondLevelCallback(config *SecondLevelIDConfig) HTTPMetricNameAlterCallback {
 	if config.AutoDiscoverThreshold > 0 {
-		config.autoDiscoverStorage = newMetricStorage(config.AutoDiscoverThreshold)
+		maxSections := config.AutoDiscoverMaxSections
+		if maxSections == 0 {
+			maxSections = DefaultAutoDiscoverMaxSections
+		}
+		maxDiscovered := config.AutoDiscoverMaxDiscovered
+		if maxDiscovered == 0 {
+			maxDiscovered = DefaultAutoDiscoverMaxDiscovered
+		}
+		config.autoDiscoverStorage = newMetricStorage(config.AutoDiscoverThreshold, maxSections, maxDiscovered, config.AutoDiscoverTTL)
 
 		// convert array to map for easier search
 		config.autoDiscoverWhiteMap = make(map[string]bool, len(config.AutoDiscoverWhiteList))
@@ -110,6 +248,11 @@ func NewHasIDAtSecondLevelCallback(config *SecondLevelIDConfig) HTTPMetricNameAl
 	}
 
 	return func(operation *MetricOperation, r *http.Request) *MetricOperation {
+		// operation depth is too small to have second level
+		if len(operation.operations) < MetricOperationsMaxLength {
+			return operation
+		}
+
 		firstFragment := "/"
 		for _, fragment := range strings.Split(r.URL.Path, "/") {
 			if fragment != "" {
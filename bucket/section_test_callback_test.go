@@ -1,6 +1,8 @@
 package bucket
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 
@@ -87,3 +89,95 @@ func TestSectionsTestsMap_String(t *testing.T) {
 
 	assert.Equal(t, "[bar: numeric, baz: not_empty, foo: true]", m.String())
 }
+
+func TestSectionTests(t *testing.T) {
+	dataProvider := []struct {
+		Test    string
+		Section PathSection
+		Result  bool
+	}{
+		{SectionTestIsUUID, "123e4567-e89b-12d3-a456-426614174000", true},
+		{SectionTestIsUUID, "123E4567-E89B-12D3-A456-426614174000", true},
+		{SectionTestIsUUID, "123e4567e89b12d3a456426614174000", false},
+		{SectionTestIsUUID, "123e4567-e89b-12d3-a456-42661417400z", false},
+		{SectionTestIsUUID, "users", false},
+
+		{SectionTestIsHex, "d41d8cd98f00b204e9800998ecf8427e", true},
+		{SectionTestIsHex, "507f1f77bcf86cd799439011", true},
+		{SectionTestIsHex, "DA39A3EE5E6B4B0D3255BFEF95601890AFD80709", true},
+		{SectionTestIsHex, "deadbeef", false},
+		{SectionTestIsHex, "added", false},
+		{SectionTestIsHex, "507f1f77bcf86cd79943901g", false},
+
+		{SectionTestIsULID, "01ARZ3NDEKTSV4RRFFQ69G5FAV", true},
+		{SectionTestIsULID, "01arz3ndektsv4rrffq69g5fav", true},
+		{SectionTestIsULID, "81ARZ3NDEKTSV4RRFFQ69G5FAV", false},
+		{SectionTestIsULID, "01ARZ3NDEKTSV4RRFFQ69G5FAU", false},
+		{SectionTestIsULID, "01ARZ3NDEKTSV4RRFFQ69G5FA", false},
+
+		{SectionTestIsDate, "2020-02-29", true},
+		{SectionTestIsDate, "2020-02-30", false},
+		{SectionTestIsDate, "2020-01-02T15:04:05Z", true},
+		{SectionTestIsDate, "2020-01-02T15:04:05.999+02:00", true},
+		{SectionTestIsDate, "today", false},
+
+		{SectionTestIsBase64, "dXNlcjoxMjM0NTY3ODk=", true},
+		{SectionTestIsBase64, "dXNlcjoxMjM0NTY3ODkw", true},
+		{SectionTestIsBase64, "eyJhbGciOiJIUzI1NiJ9", true},
+		{SectionTestIsBase64, "a-b_c-d_e-f_g-h_12", false},
+		{SectionTestIsBase64, "summer2024-sales", false},
+		{SectionTestIsBase64, "product2024items", false},
+		{SectionTestIsBase64, "summer2024-sales-promo", false},
+		{SectionTestIsBase64, "product2024items-promo", false},
+		{SectionTestIsBase64, "dXNlcjoxMjM0NTY3ODk", false},
+		{SectionTestIsBase64, "internationalization", false},
+		{SectionTestIsBase64, "12345678901234567890", false},
+		{SectionTestIsBase64, "dXNlcjox", false},
+		{SectionTestIsBase64, "dXNlcjoxMjM0NTY3O!k=", false},
+
+		{SectionTestIsEmail, "john.doe@example.com", true},
+		{SectionTestIsEmail, "john+tag@mail.example.co.uk", true},
+		{SectionTestIsEmail, "john.doe@localhost", false},
+		{SectionTestIsEmail, "@example.com", false},
+		{SectionTestIsEmail, "john@doe@example.com", false},
+
+		{SectionTestIsID, "12345", true},
+		{SectionTestIsID, "123e4567-e89b-12d3-a456-426614174000", true},
+		{SectionTestIsID, "507f1f77bcf86cd799439011", true},
+		{SectionTestIsID, "01ARZ3NDEKTSV4RRFFQ69G5FAV", true},
+		{SectionTestIsID, "2020-01-02", true},
+		{SectionTestIsID, "dXNlcjoxMjM0NTY3ODk=", true},
+		{SectionTestIsID, "john.doe@example.com", true},
+		{SectionTestIsID, "search", false},
+		{SectionTestIsID, "summer2024-sales", false},
+		{SectionTestIsID, "product2024items", false},
+		{SectionTestIsID, "black-friday-2024-deals", false},
+		{SectionTestIsID, "delivery_options", false},
+		{SectionTestIsID, MetricEmptyPlaceholder, false},
+	}
+
+	for _, data := range dataProvider {
+		callback := GetSectionTestCallback(data.Test)
+		require.NotNil(t, callback, data.Test)
+		assert.Equal(t, data.Result, callback(data.Section), "%s: %s", data.Test, data.Section)
+	}
+}
+
+func TestParseSectionsTestsMap_BuiltInTests(t *testing.T) {
+	m, err := ParseSectionsTestsMap("users:uuid:hashes:hex\nevents:ulid:reports:date:tokens:base64:subscribers:email:orders:id")
+	require.NoError(t, err)
+	assert.Equal(t, "[events: ulid, hashes: hex, orders: id, reports: date, subscribers: email, tokens: base64, users: uuid]", m.String())
+
+	callback := NewHasIDAtSecondLevelCallback(&SecondLevelIDConfig{HasIDAtSecondLevel: m})
+	for path, metric := range map[string]string{
+		"/users/123e4567-e89b-12d3-a456-426614174000": "request.get.users.-id-",
+		"/users/me":                          "request.get.users.me",
+		"/orders/01ARZ3NDEKTSV4RRFFQ69G5FAV": "request.get.orders.-id-",
+		"/orders/search":                     "request.get.orders.search",
+		"/orders/summer2024-sales":           "request.get.orders.summer2024-sales",
+		"/reports/2020-01-02":                "request.get.reports.-id-",
+	} {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		assert.Equal(t, metric, NewHTTPRequest(SectionRequest, r, true, callback, false).Metric(), path)
+	}
+}
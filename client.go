@@ -5,6 +5,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hellofresh/stats-go/client"
 	"github.com/hellofresh/stats-go/incrementer"
@@ -14,6 +15,18 @@ import (
 const (
 	// StatsD is a dsn scheme value for statsd client
 	statsD = "statsd"
+	// dogStatsD is a dsn scheme value for dogstatsd client
+	dogStatsD = "dogstatsd"
+	// tcpSuffix is a dsn scheme suffix for statsd and dogstatsd clients over TCP, e.g. "statsd+tcp"
+	tcpSuffix = "+tcp"
+	// unixSuffix is a dsn scheme suffix for statsd and dogstatsd clients over unix datagram socket, e.g. "statsd+unix"
+	unixSuffix = "+unix"
+	// influx is a dsn scheme value for influx line protocol client
+	influx = "influx"
+	// graphite is a dsn scheme value for graphite plaintext protocol client
+	graphite = "graphite"
+	// otlp is a dsn scheme value for OpenTelemetry collector client
+	otlp = "otlp"
 	// prometheus is a dsn scheme value for prometheus client
 	prometheus = "prometheus"
 	// Log is a dsn scheme value for log client
@@ -22,11 +35,35 @@ const (
 	memory = "memory"
 	// Noop is a dsn scheme value for noop client
 	noop = "noop"
+	// multi is a dsn scheme value for client that forwards metrics to several clients
+	multi = "multi"
+	// registryDefault is a prometheus client registry dsn option value for prometheus default registry
+	registryDefault = "default"
+	// registryDedicated is a prometheus client registry dsn option value for dedicated registry
+	registryDedicated = "dedicated"
 )
 
 // ErrUnknownClient is an error returned when trying to create stats client of unknown type
 var ErrUnknownClient = errors.New("unknown stats client type")
 
+// ErrUnknownTransport is an error returned when trying to create stats client with unknown transport
+var ErrUnknownTransport = errors.New("unknown stats client transport")
+
+// ErrNoClients is an error returned when trying to create multi client w/out any client dsn
+var ErrNoClients = errors.New("no stats clients dsn set for multi client")
+
+// ErrInvalidPercentiles is an error returned when statsd client timer percentiles can not be parsed
+var ErrInvalidPercentiles = errors.New("invalid timer percentiles, must be comma-separated numbers in (0, 100] range")
+
+// ErrInvalidOperationDepth is an error returned when metric operation depth can not be parsed
+var ErrInvalidOperationDepth = errors.New("invalid operation depth, must be positive integer")
+
+// ErrInvalidMaxSeries is an error returned when prometheus client label values combinations limit can not be parsed
+var ErrInvalidMaxSeries = errors.New("invalid max series, must be non-negative integer")
+
+// ErrInvalidRegistry is an error returned when prometheus client registry is unknown
+var ErrInvalidRegistry = errors.New("invalid prometheus registry, must be one of default or dedicated")
+
 // NewClient creates and builds new stats client instance by given dsn
 func NewClient(dsn string) (client.Client, error) {
 	dsnURL, err := url.Parse(dsn)
@@ -37,18 +74,264 @@ func NewClient(dsn string) (client.Client, error) {
 	// do not care about parse error, as default value is set to false that is fine for us
 	unicode, _ := strconv.ParseBool(dsnURL.Query().Get("unicode"))
 
+	depth := dsnURL.Query().Get("depth")
+	if depth == "" {
+		return newClient(dsnURL, unicode)
+	}
+
+	operationDepth, err := strconv.Atoi(depth)
+	if err != nil || operationDepth <= 0 {
+		return nil, ErrInvalidOperationDepth
+	}
+
+	statsClient, err := newClient(dsnURL, unicode)
+	if err != nil {
+		return nil, err
+	}
+
+	return statsClient.SetOperationDepth(operationDepth), nil
+}
+
+// newClient creates stats client instance for dsn scheme
+func newClient(dsnURL *url.URL, unicode bool) (client.Client, error) {
 	switch dsnURL.Scheme {
-	case statsD:
-		return client.NewStatsD(dsnURL.Host, strings.Trim(dsnURL.Path, "/"), unicode)
+	case statsD, statsD + tcpSuffix, statsD + unixSuffix, dogStatsD, dogStatsD + tcpSuffix, dogStatsD + unixSuffix:
+		return newStatsDClient(dsnURL, unicode)
+	case influx:
+		return newInfluxClient(dsnURL, unicode)
+	case graphite:
+		// do not care about parse error, as default value is set to zero that is replaced with default interval
+		flushInterval, _ := time.ParseDuration(dsnURL.Query().Get("flush"))
+		return client.NewGraphite(dsnURL.Host, strings.Trim(dsnURL.Path, "/"), flushInterval, unicode)
+	case otlp:
+		return newOTLPClient(dsnURL, unicode), nil
 	case prometheus:
-		return client.NewPrometheus(dsnURL.Host, incrementer.NewPrometheusIncrementerFactory(), state.NewPrometheusStateFactory()), nil
+		return newPrometheusClient(dsnURL)
 	case log:
 		return client.NewLog(unicode), nil
 	case memory:
 		return client.NewMemory(unicode), nil
 	case noop:
 		return client.NewNoop(), nil
+	case multi:
+		return newMultiClient(dsnURL)
 	}
 
 	return nil, ErrUnknownClient
 }
+
+// newMultiClient creates client that forwards metrics to all clients set with "dsn" query parameters,
+// e.g. "multi://?dsn=statsd://localhost:8125/prefix&dsn=prometheus://namespace", client dsn query must be url-encoded
+func newMultiClient(dsnURL *url.URL) (client.Client, error) {
+	dsns := dsnURL.Query()["dsn"]
+	if len(dsns) == 0 {
+		return nil, ErrNoClients
+	}
+
+	clients := make([]client.Client, 0, len(dsns))
+	for _, dsn := range dsns {
+		c, err := NewClient(dsn)
+		if err != nil {
+			// close already created clients to not leak their connections
+			client.NewMulti(clients...).Close()
+			return nil, err
+		}
+
+		clients = append(clients, c)
+	}
+
+	return client.NewMulti(clients...), nil
+}
+
+// newStatsDClient creates statsd or dogstatsd client for given dsn, the following query parameters are supported:
+// "rate" - default client-side sample rate and "rate.<section>" - sample rate for the given section,
+// "aggregate" - statsd client aggregation mode flush interval, "percentiles" - comma-separated timer percentiles
+// statsd client computes in aggregation mode, "mtu" - max size of metrics batch sent at once, "retry" - interval
+// to retry connection with if statsd is not available, metrics are logged with log client until connected.
+// Scheme "+tcp" and "+unix" suffixes set network, "+unix" one is for unix datagram socket, dsn path is a socket path
+// and prefix is set with "prefix" query parameter, e.g. "statsd+unix:///var/run/statsd.sock?prefix=my.app"
+func newStatsDClient(dsnURL *url.URL, unicode bool) (client.Client, error) {
+	query := dsnURL.Query()
+	scheme := dsnURL.Scheme
+	addr, prefix := dsnURL.Host, strings.Trim(dsnURL.Path, "/")
+
+	var opts []client.StatsDOption
+	switch {
+	case strings.HasSuffix(scheme, tcpSuffix):
+		scheme = strings.TrimSuffix(scheme, tcpSuffix)
+		opts = append(opts, client.WithNetwork("tcp"))
+	case strings.HasSuffix(scheme, unixSuffix):
+		scheme = strings.TrimSuffix(scheme, unixSuffix)
+		addr, prefix = dsnURL.Path, query.Get("prefix")
+		opts = append(opts, client.WithNetwork("unixgram"))
+	}
+
+	// do not care about parse error, as default value is set to zero that is replaced with default packet size
+	if mtu, _ := strconv.Atoi(query.Get("mtu")); mtu > 0 {
+		opts = append(opts, client.WithMaxPacketSize(mtu))
+	}
+
+	if aggregate := query.Get("aggregate"); aggregate != "" {
+		flushInterval, err := time.ParseDuration(aggregate)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, client.WithAggregation(flushInterval))
+	}
+
+	if percentiles := query.Get("percentiles"); percentiles != "" {
+		var values []float64
+		for _, p := range strings.Split(percentiles, ",") {
+			value, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+			if err != nil || value <= 0 || value > 100 {
+				return nil, ErrInvalidPercentiles
+			}
+			values = append(values, value)
+		}
+		opts = append(opts, client.WithAggregationPercentiles(values...))
+	}
+
+	for key, values := range query {
+		if key != "rate" && !strings.HasPrefix(key, "rate.") {
+			continue
+		}
+
+		rate, err := client.ParseSampleRate(values[0])
+		if err != nil {
+			return nil, err
+		}
+
+		if key == "rate" {
+			opts = append(opts, client.WithSampleRate(rate))
+		} else {
+			opts = append(opts, client.WithSectionSampleRate(strings.TrimPrefix(key, "rate."), rate))
+		}
+	}
+
+	connect := func() (client.Client, error) {
+		if scheme == dogStatsD {
+			return client.NewDogStatsD(addr, prefix, unicode, opts...)
+		}
+		return client.NewStatsD(addr, prefix, unicode, opts...)
+	}
+
+	statsClient, err := connect()
+	switch {
+	case err == nil:
+		return statsClient, nil
+	case err == client.ErrUnknownStatsDNetwork:
+		return nil, err
+	}
+
+	// do not care about parse error, as default value is set to zero that is replaced with default interval
+	retryInterval, _ := time.ParseDuration(query.Get("retry"))
+
+	return client.NewFallback(connect, client.NewLog(unicode), retryInterval), nil
+}
+
+// newInfluxClient creates influx client for given dsn, transport is set with "transport" query parameter
+// and can be one of "udp" (default), "http" or "https", database for HTTP transport is set with "db" query parameter
+func newInfluxClient(dsnURL *url.URL, unicode bool) (client.Client, error) {
+	prefix := strings.Trim(dsnURL.Path, "/")
+
+	switch transport := dsnURL.Query().Get("transport"); transport {
+	case "", "udp":
+		return client.NewInfluxUDP(dsnURL.Host, prefix, unicode)
+	case "http", "https":
+		return client.NewInfluxHTTP(transport+"://"+dsnURL.Host, dsnURL.Query().Get("db"), prefix, unicode)
+	}
+
+	return nil, ErrUnknownTransport
+}
+
+// newOTLPClient creates OpenTelemetry collector client for given dsn, the following query parameters are supported:
+// "tls" - use https to connect to collector, "service" - service name resource attribute, "interval" - export interval
+func newOTLPClient(dsnURL *url.URL, unicode bool) client.Client {
+	scheme := "http"
+	// do not care about parse error, as default value is set to false that is fine for us
+	if useTLS, _ := strconv.ParseBool(dsnURL.Query().Get("tls")); useTLS {
+		scheme = "https"
+	}
+
+	// do not care about parse error, as default value is set to zero that is replaced with default interval
+	interval, _ := time.ParseDuration(dsnURL.Query().Get("interval"))
+
+	return client.NewOTLP(
+		scheme+"://"+dsnURL.Host,
+		strings.Trim(dsnURL.Path, "/"),
+		dsnURL.Query().Get("service"),
+		interval,
+		unicode,
+	)
+}
+
+// newPrometheusClient creates prometheus client for given dsn, the following query parameters are supported:
+// "registry" - "default" to register metrics in prometheus default registry, that is the default value,
+// or "dedicated" to register them in dedicated registry with go runtime and process collectors, see
+// client.NewPrometheusRegistry, "push" - Pushgateway url to enable push mode, "job" - Pushgateway job name,
+// default value is namespace,
+// "push_interval" - interval to push metrics with, "buckets" - default operation timing histogram buckets,
+// "buckets.<section>" - buckets for the given section, see client.ParseHistogramBuckets for format,
+// and "max_series" - label values combinations limit per metric, see client.WithMaxLabelCardinality
+func newPrometheusClient(dsnURL *url.URL) (client.Client, error) {
+	var opts []client.PrometheusOption
+
+	query := dsnURL.Query()
+	for key, values := range query {
+		if key != "buckets" && !strings.HasPrefix(key, "buckets.") {
+			continue
+		}
+
+		buckets, err := client.ParseHistogramBuckets(values[0])
+		if err != nil {
+			return nil, err
+		}
+
+		if key == "buckets" {
+			opts = append(opts, client.WithDefaultHistogramBuckets(buckets))
+		} else {
+			opts = append(opts, client.WithHistogramBuckets(strings.TrimPrefix(key, "buckets."), buckets))
+		}
+	}
+
+	if maxSeries := query.Get("max_series"); maxSeries != "" {
+		limit, err := strconv.Atoi(maxSeries)
+		if err != nil || limit < 0 {
+			return nil, ErrInvalidMaxSeries
+		}
+		opts = append(opts, client.WithMaxLabelCardinality(limit))
+	}
+
+	if pushURL := query.Get("push"); pushURL != "" {
+		job := query.Get("job")
+		if job == "" {
+			job = dsnURL.Host
+		}
+
+		// do not care about parse error, as default value is set to zero that is replaced with default interval
+		interval, _ := time.ParseDuration(query.Get("push_interval"))
+		opts = append(opts, client.WithPushGateway(pushURL, job, interval))
+	}
+
+	switch query.Get("registry") {
+	case "", registryDefault:
+		return client.NewPrometheus(
+			dsnURL.Host,
+			incrementer.NewPrometheusIncrementerFactory(),
+			state.NewPrometheusStateFactory(),
+			opts...,
+		), nil
+	case registryDedicated:
+		registry := client.NewPrometheusRegistry()
+		opts = append(opts, client.WithRegistry(registry, registry))
+
+		return client.NewPrometheus(
+			dsnURL.Host,
+			incrementer.NewPrometheusIncrementerFactoryWithRegisterer(registry),
+			state.NewPrometheusStateFactoryWithRegisterer(registry),
+			opts...,
+		), nil
+	}
+
+	return nil, ErrInvalidRegistry
+}
@@ -0,0 +1,416 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hellofresh/stats-go/bucket"
+	"github.com/hellofresh/stats-go/log"
+	"github.com/hellofresh/stats-go/timer"
+)
+
+const (
+	// DefaultAsyncBufferSize is default number of events Async client buffers before applying overflow policy
+	DefaultAsyncBufferSize = 1024
+	// DefaultAsyncCloseTimeout is default time Async client waits for buffered events to be processed on close
+	DefaultAsyncCloseTimeout = 5 * time.Second
+)
+
+// AsyncOverflowPolicy defines Async client behaviour when events buffer is full
+type AsyncOverflowPolicy int
+
+const (
+	// AsyncDrop drops event when events buffer is full, so that tracking never blocks caller
+	AsyncDrop AsyncOverflowPolicy = iota
+	// AsyncBlock blocks caller until there is a room for event in events buffer
+	AsyncBlock
+)
+
+// ErrAsyncCloseTimeout is an error returned when Async client failed to process buffered events before close timeout
+var ErrAsyncCloseTimeout = errors.New("async stats client close timed out, buffered events are dropped")
+
+// AsyncOption is a function that configures Async client
+type AsyncOption func(*Async)
+
+// WithAsyncBufferSize sets number of events Async client buffers before applying overflow policy
+func WithAsyncBufferSize(size int) AsyncOption {
+	return func(c *Async) {
+		c.bufferSize = size
+	}
+}
+
+// WithAsyncOverflowPolicy sets Async client behaviour when events buffer is full
+func WithAsyncOverflowPolicy(policy AsyncOverflowPolicy) AsyncOption {
+	return func(c *Async) {
+		c.policy = policy
+	}
+}
+
+// WithAsyncCloseTimeout sets time Async client waits for buffered events to be processed on close
+func WithAsyncCloseTimeout(timeout time.Duration) AsyncOption {
+	return func(c *Async) {
+		c.closeTimeout = timeout
+	}
+}
+
+// asyncEvent is a tracking call that is applied to wrapped client in background goroutine
+type asyncEvent func(client Client)
+
+// Async is Client implementation that wraps another client and moves all the tracking work to background goroutine
+// through bounded events buffer, so that slow backend does not slow down caller. Timers are finished at the moment
+// of tracking call and metric operations are copied, so that it is safe to reuse them after the call.
+// HTTP metric callback and section setters, BuildTimer and Handler are applied to wrapped client synchronously.
+type Async struct {
+	// dropped is accessed atomically and goes first to be 64-bit aligned on 32-bit platforms
+	dropped uint64
+
+	sync.Mutex
+
+	client       Client
+	bufferSize   int
+	policy       AsyncOverflowPolicy
+	closeTimeout time.Duration
+
+	events  chan asyncEvent
+	closing chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	closed  bool
+}
+
+// NewAsync builds and returns new Async instance wrapping given client
+func NewAsync(client Client, opts ...AsyncOption) *Async {
+	c := &Async{
+		client:       client,
+		bufferSize:   DefaultAsyncBufferSize,
+		policy:       AsyncDrop,
+		closeTimeout: DefaultAsyncCloseTimeout,
+		closing:      make(chan struct{}),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.bufferSize < 0 {
+		c.bufferSize = 0
+	}
+	c.events = make(chan asyncEvent, c.bufferSize)
+
+	go c.run()
+
+	return c
+}
+
+// Client returns wrapped client instance
+func (c *Async) Client() Client {
+	return c.client
+}
+
+// Dropped returns number of events dropped because of full events buffer or close timeout
+func (c *Async) Dropped() uint64 {
+	return atomic.LoadUint64(&c.dropped)
+}
+
+// Pending returns number of buffered events that are not processed yet
+func (c *Async) Pending() int {
+	return len(c.events)
+}
+
+func (c *Async) run() {
+	defer close(c.done)
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case event := <-c.events:
+			if !c.handle(event) {
+				return
+			}
+		case <-c.closing:
+			// process buffered events until buffer is empty or close times out
+			for {
+				select {
+				case <-c.stop:
+					return
+				case event := <-c.events:
+					if !c.handle(event) {
+						return
+					}
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// handle processes event unless close timed out while waiting for the previous event to be processed
+func (c *Async) handle(event asyncEvent) bool {
+	select {
+	case <-c.stop:
+		atomic.AddUint64(&c.dropped, 1)
+		return false
+	default:
+	}
+
+	event(c.client)
+	return true
+}
+
+// enqueue adds event to the buffer applying overflow policy if it is full. Caller is not blocked
+// after client is closed, so that close is not blocked by callers waiting for room in the buffer.
+func (c *Async) enqueue(event asyncEvent) {
+	select {
+	case <-c.closing:
+		atomic.AddUint64(&c.dropped, 1)
+		return
+	default:
+	}
+
+	if c.policy == AsyncBlock {
+		select {
+		case c.events <- event:
+		case <-c.closing:
+			atomic.AddUint64(&c.dropped, 1)
+		}
+		return
+	}
+
+	select {
+	case c.events <- event:
+	default:
+		atomic.AddUint64(&c.dropped, 1)
+	}
+}
+
+// finishedTimer returns timer with duration fixed at the moment of tracking call
+func finishedTimer(t timer.Timer) timer.Timer {
+	if nil == t {
+		return nil
+	}
+
+	return timer.NewDuration(t.Finish())
+}
+
+// BuildTimer builds timer to track metric timings
+func (c *Async) BuildTimer() timer.Timer {
+	return c.client.BuildTimer()
+}
+
+// Close waits for buffered events to be processed within close timeout and closes wrapped client,
+// returns ErrAsyncCloseTimeout if not all the events were processed in time, in this case the rest of buffered
+// events are dropped and wrapped client is closed in background once the event being processed returns
+func (c *Async) Close() error {
+	c.Lock()
+	if c.closed {
+		c.Unlock()
+		return nil
+	}
+	c.closed = true
+	close(c.closing)
+	c.Unlock()
+
+	select {
+	case <-c.done:
+		return c.closeClient()
+	case <-time.After(c.closeTimeout):
+		close(c.stop)
+		go func() {
+			if err := c.closeClient(); err != nil {
+				log.Log("An error occurred while closing async wrapped stats client", nil, err)
+			}
+		}()
+
+		log.Log("Async stats client close timed out", map[string]interface{}{"pending": len(c.events)}, nil)
+		return ErrAsyncCloseTimeout
+	}
+}
+
+// closeClient waits for background goroutine to stop, counts events left in the buffer as dropped
+// and closes wrapped client
+func (c *Async) closeClient() error {
+	<-c.done
+
+	atomic.AddUint64(&c.dropped, uint64(len(c.events)))
+	if dropped := c.Dropped(); dropped > 0 {
+		log.Log("Async stats client dropped events", map[string]interface{}{"dropped": dropped}, nil)
+	}
+
+	return c.client.Close()
+}
+
+// TrackRequest tracks HTTP Request stats
+func (c *Async) TrackRequest(r *http.Request, t timer.Timer, success bool) Client {
+	// shallow copy, so that request fields reassigned by caller after the call do not affect metric
+	req := *r
+	t = finishedTimer(t)
+
+	c.enqueue(func(client Client) {
+		client.TrackRequest(&req, t, success)
+	})
+
+	return c
+}
+
+// TrackOperation tracks custom operation
+func (c *Async) TrackOperation(section string, operation *bucket.MetricOperation, t timer.Timer, success bool) Client {
+	operation = operation.Clone()
+	t = finishedTimer(t)
+
+	c.enqueue(func(client Client) {
+		client.TrackOperation(section, operation, t, success)
+	})
+
+	return c
+}
+
+// TrackOperationN tracks custom operation with n diff
+func (c *Async) TrackOperationN(section string, operation *bucket.MetricOperation, t timer.Timer, n int, success bool) Client {
+	operation = operation.Clone()
+	t = finishedTimer(t)
+
+	c.enqueue(func(client Client) {
+		client.TrackOperationN(section, operation, t, n, success)
+	})
+
+	return c
+}
+
+// TrackMetric tracks custom metric, w/out ok/fail additional sections
+func (c *Async) TrackMetric(section string, operation *bucket.MetricOperation) Client {
+	operation = operation.Clone()
+
+	c.enqueue(func(client Client) {
+		client.TrackMetric(section, operation)
+	})
+
+	return c
+}
+
+// TrackMetricN tracks custom metric with n diff, w/out ok/fail additional sections
+func (c *Async) TrackMetricN(section string, operation *bucket.MetricOperation, n int) Client {
+	operation = operation.Clone()
+
+	c.enqueue(func(client Client) {
+		client.TrackMetricN(section, operation, n)
+	})
+
+	return c
+}
+
+// TrackState tracks metric absolute value
+func (c *Async) TrackState(section string, operation *bucket.MetricOperation, value int) Client {
+	operation = operation.Clone()
+
+	c.enqueue(func(client Client) {
+		client.TrackState(section, operation, value)
+	})
+
+	return c
+}
+
+// TrackStateFloat tracks metric absolute float value
+func (c *Async) TrackStateFloat(section string, operation *bucket.MetricOperation, value float64) Client {
+	operation = operation.Clone()
+
+	c.enqueue(func(client Client) {
+		client.TrackStateFloat(section, operation, value)
+	})
+
+	return c
+}
+
+// TrackStateAdd increases metric state by delta
+func (c *Async) TrackStateAdd(section string, operation *bucket.MetricOperation, delta float64) Client {
+	operation = operation.Clone()
+
+	c.enqueue(func(client Client) {
+		client.TrackStateAdd(section, operation, delta)
+	})
+
+	return c
+}
+
+// TrackStateSub decreases metric state by delta
+func (c *Async) TrackStateSub(section string, operation *bucket.MetricOperation, delta float64) Client {
+	operation = operation.Clone()
+
+	c.enqueue(func(client Client) {
+		client.TrackStateSub(section, operation, delta)
+	})
+
+	return c
+}
+
+// TrackSummary tracks metric value distribution
+func (c *Async) TrackSummary(section string, operation *bucket.MetricOperation, value float64) Client {
+	operation = operation.Clone()
+
+	c.enqueue(func(client Client) {
+		client.TrackSummary(section, operation, value)
+	})
+
+	return c
+}
+
+// TrackUnique tracks number of unique values
+func (c *Async) TrackUnique(section string, operation *bucket.MetricOperation, value string) Client {
+	operation = operation.Clone()
+
+	c.enqueue(func(client Client) {
+		client.TrackUnique(section, operation, value)
+	})
+
+	return c
+}
+
+// SetHTTPMetricCallback sets callback handler that allows metric operation alteration for HTTP Request
+func (c *Async) SetHTTPMetricCallback(callback bucket.HTTPMetricNameAlterCallback) Client {
+	c.client.SetHTTPMetricCallback(callback)
+	return c
+}
+
+// GetHTTPMetricCallback gets callback handler that allows metric operation alteration for HTTP Request
+func (c *Async) GetHTTPMetricCallback() bucket.HTTPMetricNameAlterCallback {
+	return c.client.GetHTTPMetricCallback()
+}
+
+// SetHTTPRequestSection sets metric section for HTTP Request metrics
+func (c *Async) SetHTTPRequestSection(section string) Client {
+	c.client.SetHTTPRequestSection(section)
+	return c
+}
+
+// ResetHTTPRequestSection resets metric section for HTTP Request metrics to default value that is "request"
+func (c *Async) ResetHTTPRequestSection() Client {
+	c.client.ResetHTTPRequestSection()
+	return c
+}
+
+// SetOperationDepth sets number of operations in metric names of wrapped client
+func (c *Async) SetOperationDepth(depth int) Client {
+	c.client.SetOperationDepth(depth)
+	return c
+}
+
+// With returns child client that adds given labels to every tracked metric operation
+func (c *Async) With(labels map[string]string) Client {
+	return newScoped(c, labels, "")
+}
+
+// WithSection returns child client that prefixes every tracked metric section with the given prefix
+func (c *Async) WithSection(prefix string) Client {
+	return newScoped(c, nil, prefix)
+}
+
+// Handler returns metrics endpoint for prometheus backend
+func (c *Async) Handler() http.Handler {
+	return c.client.Handler()
+}
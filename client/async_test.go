@@ -0,0 +1,190 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hellofresh/stats-go/bucket"
+	"github.com/hellofresh/stats-go/timer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingClient records tracked metrics and blocks tracking until unblocked, if block channel is set
+type recordingClient struct {
+	*Noop
+	sync.Mutex
+
+	block   chan struct{}
+	metrics []string
+	labels  []map[string]string
+	timings []time.Duration
+	closed  bool
+}
+
+func (c *recordingClient) wait() {
+	if c.block != nil {
+		<-c.block
+	}
+}
+
+func (c *recordingClient) TrackMetric(section string, operation *bucket.MetricOperation) Client {
+	c.wait()
+
+	c.Lock()
+	defer c.Unlock()
+	c.metrics = append(c.metrics, bucket.NewPlain(section, operation, true, false).Metric())
+	c.labels = append(c.labels, operation.Labels)
+	return c
+}
+
+func (c *recordingClient) TrackRequest(r *http.Request, t timer.Timer, success bool) Client {
+	c.wait()
+
+	c.Lock()
+	defer c.Unlock()
+	c.metrics = append(c.metrics, r.URL.Path)
+	c.timings = append(c.timings, t.Finish())
+	return c
+}
+
+// isClosed checks if client was closed, client may be closed in background on close timeout
+func (c *recordingClient) isClosed() bool {
+	c.Lock()
+	defer c.Unlock()
+	return c.closed
+}
+
+// tracked returns number of tracked metrics
+func (c *recordingClient) tracked() int {
+	c.Lock()
+	defer c.Unlock()
+	return len(c.metrics)
+}
+
+func (c *recordingClient) Close() error {
+	c.Lock()
+	defer c.Unlock()
+	c.closed = true
+	return nil
+}
+
+func TestAsync_Track(t *testing.T) {
+	wrapped := &recordingClient{Noop: NewNoop()}
+	client := NewAsync(wrapped)
+
+	operation := bucket.NewMetricOperation("foo").WithLabels(map[string]string{"country": "de"})
+	client.TrackMetric("section", operation)
+	operation.Labels["country"] = "at"
+
+	client.TrackRequest(&http.Request{Method: http.MethodGet, URL: &url.URL{Path: "/foo"}}, timer.NewDuration(time.Second), true)
+
+	require.NoError(t, client.Close())
+	assert.True(t, wrapped.closed)
+	assert.Equal(t, []string{"section.foo.-.-", "/foo"}, wrapped.metrics)
+	assert.Equal(t, []map[string]string{{"country": "de"}}, wrapped.labels)
+	assert.Equal(t, []time.Duration{time.Second}, wrapped.timings)
+	assert.Equal(t, uint64(0), client.Dropped())
+
+	// tracking after close is dropped
+	client.TrackMetric("section", operation)
+	assert.Equal(t, uint64(1), client.Dropped())
+	assert.NoError(t, client.Close())
+}
+
+func TestAsync_Drop(t *testing.T) {
+	wrapped := &recordingClient{Noop: NewNoop(), block: make(chan struct{})}
+	client := NewAsync(wrapped, WithAsyncBufferSize(2), WithAsyncOverflowPolicy(AsyncDrop))
+
+	operation := bucket.NewMetricOperation("foo")
+	for i := 0; i < 10; i++ {
+		client.TrackMetric("section", operation)
+	}
+
+	// one event is taken by background goroutine, two are buffered and the rest are dropped
+	assert.True(t, client.Dropped() >= 7)
+
+	close(wrapped.block)
+	require.NoError(t, client.Close())
+	assert.Equal(t, 10, len(wrapped.metrics)+int(client.Dropped()))
+}
+
+func TestAsync_Block(t *testing.T) {
+	wrapped := &recordingClient{Noop: NewNoop(), block: make(chan struct{})}
+	client := NewAsync(wrapped, WithAsyncBufferSize(1), WithAsyncOverflowPolicy(AsyncBlock))
+
+	tracked := make(chan struct{})
+	go func() {
+		for i := 0; i < 5; i++ {
+			client.TrackMetric("section", bucket.NewMetricOperation("foo"))
+		}
+		close(tracked)
+	}()
+
+	select {
+	case <-tracked:
+		t.Fatal("tracking is expected to block while buffer is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(wrapped.block)
+	<-tracked
+	require.NoError(t, client.Close())
+	assert.Len(t, wrapped.metrics, 5)
+	assert.Equal(t, uint64(0), client.Dropped())
+}
+
+func TestAsync_CloseTimeout(t *testing.T) {
+	wrapped := &recordingClient{Noop: NewNoop(), block: make(chan struct{})}
+	client := NewAsync(wrapped, WithAsyncBufferSize(10), WithAsyncCloseTimeout(50*time.Millisecond))
+
+	for i := 0; i < 5; i++ {
+		client.TrackMetric("section", bucket.NewMetricOperation("foo"))
+	}
+
+	// unblock the event that is being processed only after close timeout
+	time.AfterFunc(100*time.Millisecond, func() { close(wrapped.block) })
+
+	assert.Equal(t, ErrAsyncCloseTimeout, client.Close())
+	require.Eventually(t, wrapped.isClosed, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 5, wrapped.tracked()+int(client.Dropped()))
+	assert.True(t, client.Dropped() >= 3)
+}
+
+func TestAsync_CloseTimeoutBlockedCallers(t *testing.T) {
+	wrapped := &recordingClient{Noop: NewNoop(), block: make(chan struct{})}
+	client := NewAsync(wrapped, WithAsyncBufferSize(1), WithAsyncOverflowPolicy(AsyncBlock), WithAsyncCloseTimeout(50*time.Millisecond))
+
+	tracked := make(chan struct{})
+	go func() {
+		for i := 0; i < 5; i++ {
+			client.TrackMetric("section", bucket.NewMetricOperation("foo"))
+		}
+		close(tracked)
+	}()
+
+	// wait for the caller to block on full buffer while backend is stalled
+	require.Eventually(t, func() bool { return client.Pending() == 1 }, time.Second, 5*time.Millisecond)
+
+	closed := make(chan error)
+	go func() {
+		closed <- client.Close()
+	}()
+
+	select {
+	case err := <-closed:
+		assert.Equal(t, ErrAsyncCloseTimeout, err)
+	case <-time.After(time.Second):
+		t.Fatal("close is expected to return after close timeout while backend is stalled")
+	}
+
+	// blocked caller is released on close and its events are dropped
+	<-tracked
+
+	close(wrapped.block)
+	require.Eventually(t, wrapped.isClosed, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 5, wrapped.tracked()+int(client.Dropped()))
+}
@@ -33,6 +33,21 @@ type Client interface {
 	// TrackState tracks metric absolute value
 	TrackState(section string, operation *bucket.MetricOperation, value int) Client
 
+	// TrackStateFloat tracks metric absolute float value
+	TrackStateFloat(section string, operation *bucket.MetricOperation, value float64) Client
+
+	// TrackStateAdd increases metric state by delta
+	TrackStateAdd(section string, operation *bucket.MetricOperation, delta float64) Client
+
+	// TrackStateSub decreases metric state by delta
+	TrackStateSub(section string, operation *bucket.MetricOperation, delta float64) Client
+
+	// TrackSummary tracks metric value distribution, e.g. payload sizes, queue lag or batch sizes
+	TrackSummary(section string, operation *bucket.MetricOperation, value float64) Client
+
+	// TrackUnique tracks number of unique values, e.g. users, SKUs or tenants
+	TrackUnique(section string, operation *bucket.MetricOperation, value string) Client
+
 	// SetHTTPMetricCallback sets callback handler that allows metric operation alteration for HTTP Request
 	SetHTTPMetricCallback(callback bucket.HTTPMetricNameAlterCallback) Client
 
@@ -45,6 +60,16 @@ type Client interface {
 	// ResetHTTPRequestSection resets metric section for HTTP Request metrics to default value that is "request"
 	ResetHTTPRequestSection() Client
 
+	// SetOperationDepth sets number of operations in metric names, shorter operations are padded with placeholders
+	// and longer ones are truncated, default value is bucket.MetricOperationsMaxLength
+	SetOperationDepth(depth int) Client
+
 	// Handler returns metrics endpoint for prometheus backend
 	Handler() http.Handler
+
+	// With returns child client that adds given labels to every tracked metric operation
+	With(labels map[string]string) Client
+
+	// WithSection returns child client that prefixes every tracked metric section with the given prefix
+	WithSection(prefix string) Client
 }
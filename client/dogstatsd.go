@@ -0,0 +1,270 @@
+package client
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hellofresh/stats-go/bucket"
+	"github.com/hellofresh/stats-go/incrementer"
+	"github.com/hellofresh/stats-go/internal/labelset"
+	"github.com/hellofresh/stats-go/log"
+	"github.com/hellofresh/stats-go/state"
+	"github.com/hellofresh/stats-go/timer"
+)
+
+var dogStatsDTagReplacer = strings.NewReplacer(",", "_", "|", "_", "#", "_")
+
+// DogStatsD is Client implementation for DogStatsD - statsd protocol extension with native tags support.
+// Metric names and sampling are the same as for StatsD client, MetricOperation.Labels are sent as "|#key:value" tags.
+type DogStatsD struct {
+	sync.Mutex
+	client             statsdClient
+	sampleRates        sampleRates
+	httpMetricCallback bucket.HTTPMetricNameAlterCallback
+	httpRequestSection string
+	operationDepth     int
+	unicode            bool
+}
+
+// NewDogStatsD builds and returns new DogStatsD instance
+func NewDogStatsD(addr string, prefix string, unicode bool, opts ...StatsDOption) (*DogStatsD, error) {
+	o := buildStatsDOptions(opts)
+
+	log.Log("Trying to connect to dogstatsd instance", map[string]interface{}{
+		"addr":    addr,
+		"prefix":  prefix,
+		"network": o.network,
+	}, nil)
+
+	statsdClient, err := newStatsDClient(addr, prefix, true, o)
+	if err != nil {
+		log.Log("An error occurred while connecting to DogStatsD", map[string]interface{}{
+			"addr":    addr,
+			"prefix":  prefix,
+			"network": o.network,
+		}, err)
+		return nil, err
+	}
+
+	client := &DogStatsD{
+		client:      statsdClient,
+		sampleRates: o.sampleRates,
+		unicode:     unicode,
+	}
+	client.ResetHTTPRequestSection()
+
+	return client, nil
+}
+
+// tagged returns statsd client copy that sends given labels as tags with every metric
+func (c *DogStatsD) tagged(labels map[string]string) statsdClient {
+	if len(labels) == 0 {
+		return c.client
+	}
+
+	tags := make([]string, 0, len(labels)*2)
+	for _, k := range labelset.Keys(labels) {
+		tags = append(tags, dogStatsDTagReplacer.Replace(k), dogStatsDTagReplacer.Replace(labels[k]))
+	}
+
+	return c.client.WithTags(tags...)
+}
+
+// operationClient returns statsd client that sends operation labels as tags with operation or section sample rate
+func (c *DogStatsD) operationClient(section string, operation *bucket.MetricOperation) statsdClient {
+	return sampledClient(c.tagged(operation.Labels), c.sampleRates.rate(section, operation))
+}
+
+// BuildTimer builds timer to track metric timings
+func (c *DogStatsD) BuildTimer() timer.Timer {
+	return &timer.Memory{}
+}
+
+// Close dogstatsd connection
+func (c *DogStatsD) Close() error {
+	return c.client.Close()
+}
+
+// TrackRequest tracks HTTP Request stats
+func (c *DogStatsD) TrackRequest(r *http.Request, t timer.Timer, success bool) Client {
+	b := bucket.NewHTTPRequestWithDepth(c.httpRequestSection, r, success, c.httpMetricCallback, c.unicode, c.operationDepth)
+	client := sampledClient(c.client, c.sampleRates.rate(c.httpRequestSection, nil))
+	i := incrementer.NewStatsD(client)
+
+	if nil != t {
+		client.Timing(b.Metric(), int(t.Finish()/time.Millisecond))
+	}
+	i.IncrementAll(b)
+
+	return c
+}
+
+// TrackOperation tracks custom operation
+func (c *DogStatsD) TrackOperation(section string, operation *bucket.MetricOperation, t timer.Timer, success bool) Client {
+	b := bucket.NewPlainWithDepth(section, operation, success, c.unicode, c.operationDepth)
+	client := c.operationClient(section, operation)
+	i := incrementer.NewStatsD(client)
+
+	if nil != t {
+		client.Timing(b.MetricWithSuffix(), int(t.Finish()/time.Millisecond))
+	}
+	i.IncrementAll(b)
+
+	return c
+}
+
+// TrackOperationN tracks custom operation with n diff
+func (c *DogStatsD) TrackOperationN(section string, operation *bucket.MetricOperation, t timer.Timer, n int, success bool) Client {
+	b := bucket.NewPlainWithDepth(section, operation, success, c.unicode, c.operationDepth)
+	client := c.operationClient(section, operation)
+	i := incrementer.NewStatsD(client)
+
+	if nil != t {
+		client.Timing(b.MetricWithSuffix(), int(t.Finish()/time.Millisecond))
+	}
+	i.IncrementAllN(b, n)
+
+	return c
+}
+
+// TrackMetric tracks custom metric, w/out ok/fail additional sections
+func (c *DogStatsD) TrackMetric(section string, operation *bucket.MetricOperation) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
+	i := incrementer.NewStatsD(c.operationClient(section, operation))
+
+	i.Increment(b.Metric())
+	i.Increment(b.MetricTotal())
+
+	return c
+}
+
+// TrackMetricN tracks custom metric with n diff, w/out ok/fail additional sections
+func (c *DogStatsD) TrackMetricN(section string, operation *bucket.MetricOperation, n int) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
+	i := incrementer.NewStatsD(c.operationClient(section, operation))
+
+	i.IncrementN(b.Metric(), n)
+	i.IncrementN(b.MetricTotal(), n)
+
+	return c
+}
+
+// TrackState tracks metric absolute value
+func (c *DogStatsD) TrackState(section string, operation *bucket.MetricOperation, value int) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
+	s := state.NewStatsD(c.tagged(operation.Labels))
+
+	s.Set(b.Metric(), value)
+
+	return c
+}
+
+// TrackStateFloat tracks metric absolute float value
+func (c *DogStatsD) TrackStateFloat(section string, operation *bucket.MetricOperation, value float64) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
+	s := state.NewStatsD(c.tagged(operation.Labels))
+
+	s.SetFloat(b.Metric(), value)
+
+	return c
+}
+
+// TrackStateAdd increases metric state by delta
+func (c *DogStatsD) TrackStateAdd(section string, operation *bucket.MetricOperation, delta float64) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
+	tagged := c.tagged(operation.Labels)
+	s := state.NewStatsDWithGaugeWriter(tagged, tagged)
+
+	s.Add(b.Metric(), delta)
+
+	return c
+}
+
+// TrackStateSub decreases metric state by delta
+func (c *DogStatsD) TrackStateSub(section string, operation *bucket.MetricOperation, delta float64) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
+	tagged := c.tagged(operation.Labels)
+	s := state.NewStatsDWithGaugeWriter(tagged, tagged)
+
+	s.Sub(b.Metric(), delta)
+
+	return c
+}
+
+// TrackSummary tracks metric value distribution as statsd histogram
+func (c *DogStatsD) TrackSummary(section string, operation *bucket.MetricOperation, value float64) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
+
+	c.operationClient(section, operation).Histogram(b.Metric(), value)
+
+	return c
+}
+
+// TrackUnique tracks number of unique values per flush interval as statsd set
+func (c *DogStatsD) TrackUnique(section string, operation *bucket.MetricOperation, value string) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
+
+	c.tagged(operation.Labels).Unique(b.Metric(), value)
+
+	return c
+}
+
+// SetHTTPMetricCallback sets callback handler that allows metric operation alteration for HTTP Request
+func (c *DogStatsD) SetHTTPMetricCallback(callback bucket.HTTPMetricNameAlterCallback) Client {
+	c.Lock()
+	defer c.Unlock()
+
+	c.httpMetricCallback = callback
+	return c
+}
+
+// GetHTTPMetricCallback gets callback handler that allows metric operation alteration for HTTP Request
+func (c *DogStatsD) GetHTTPMetricCallback() bucket.HTTPMetricNameAlterCallback {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.httpMetricCallback
+}
+
+// SetHTTPRequestSection sets metric section for HTTP Request metrics
+func (c *DogStatsD) SetHTTPRequestSection(section string) Client {
+	c.Lock()
+	defer c.Unlock()
+
+	c.httpRequestSection = section
+	return c
+}
+
+// ResetHTTPRequestSection resets metric section for HTTP Request metrics to default value that is "request"
+func (c *DogStatsD) ResetHTTPRequestSection() Client {
+	return c.SetHTTPRequestSection(bucket.SectionRequest)
+}
+
+// SetOperationDepth sets number of operations in metric names, shorter operations are padded with placeholders
+// and longer ones are truncated, zero value means bucket.MetricOperationsMaxLength
+func (c *DogStatsD) SetOperationDepth(depth int) Client {
+	c.Lock()
+	defer c.Unlock()
+
+	c.operationDepth = depth
+	return c
+}
+
+// With returns child client that adds given labels to every tracked metric operation
+func (c *DogStatsD) With(labels map[string]string) Client {
+	return newScoped(c, labels, "")
+}
+
+// WithSection returns child client that prefixes every tracked metric section with the given prefix
+func (c *DogStatsD) WithSection(prefix string) Client {
+	return newScoped(c, nil, prefix)
+}
+
+// Handler returns metrics endpoint for prometheus backend
+func (c *DogStatsD) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+}
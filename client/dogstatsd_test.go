@@ -0,0 +1,153 @@
+package client
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hellofresh/stats-go/bucket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listenUDP(t *testing.T) *net.UDPConn {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+
+	return conn
+}
+
+func readUDPLines(t *testing.T, conn *net.UDPConn) []string {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+
+	buf := make([]byte, 65536)
+	for {
+		n, err := conn.Read(buf)
+		require.NoError(t, err)
+
+		// statsd client sends empty packets on connect to check if server is listening
+		if n > 0 {
+			return strings.Split(string(buf[:n]), "\n")
+		}
+	}
+}
+
+func TestDogStatsD_TrackMetric(t *testing.T) {
+	conn := listenUDP(t)
+	defer conn.Close()
+
+	client, err := NewDogStatsD(conn.LocalAddr().String(), "prefix", false)
+	require.NoError(t, err)
+
+	operation := bucket.NewMetricOperation("foo", "bar").WithLabels(map[string]string{"b": "2", "a": "1,2"})
+	client.TrackMetric("section", operation)
+	require.NoError(t, client.Close())
+
+	assert.Equal(t, []string{
+		"prefix.section.foo.bar.-:1|c|#a:1_2,b:2",
+		"prefix.total.section:1|c|#a:1_2,b:2",
+	}, readUDPLines(t, conn))
+}
+
+func TestDogStatsD_TrackOperation(t *testing.T) {
+	conn := listenUDP(t)
+	defer conn.Close()
+
+	client, err := NewDogStatsD(conn.LocalAddr().String(), "", false)
+	require.NoError(t, err)
+
+	operation := bucket.NewMetricOperation("foo").WithLabels(map[string]string{"tenant": "de"})
+	client.TrackOperationN("section", operation, nil, 3, false)
+	require.NoError(t, client.Close())
+
+	assert.Equal(t, []string{
+		"section.foo.-.-:3|c|#tenant:de",
+		"section-fail.foo.-.-:3|c|#tenant:de",
+		"total.section:3|c|#tenant:de",
+		"total.section-fail:3|c|#tenant:de",
+	}, readUDPLines(t, conn))
+}
+
+func TestDogStatsD_SampleRate(t *testing.T) {
+	conn := listenUDP(t)
+	defer conn.Close()
+
+	client, err := NewDogStatsD(conn.LocalAddr().String(), "", false, WithSampleRate(0.5))
+	require.NoError(t, err)
+
+	// sampling is random, so track metric enough times to get at least one of them sent
+	operation := bucket.NewMetricOperation("foo")
+	for i := 0; i < 20; i++ {
+		client.TrackMetric("section", operation)
+	}
+	client.TrackState("section", operation, 1)
+	require.NoError(t, client.Close())
+
+	lines := readUDPLines(t, conn)
+	assert.Contains(t, lines, "section.foo.-.-:1|c|@0.5")
+	assert.Contains(t, lines, "section.foo.-.-:1|g")
+	for _, line := range lines {
+		if strings.HasSuffix(line, "|c") {
+			t.Errorf("counter is sent w/out sample rate: %s", line)
+		}
+	}
+}
+
+func TestDogStatsD_TrackState(t *testing.T) {
+	conn := listenUDP(t)
+	defer conn.Close()
+
+	client, err := NewDogStatsD(conn.LocalAddr().String(), "", false)
+	require.NoError(t, err)
+
+	client.TrackState("section", bucket.NewMetricOperation("foo"), 42)
+	require.NoError(t, client.Close())
+
+	assert.Equal(t, []string{"section.foo.-.-:42|g"}, readUDPLines(t, conn))
+}
+
+func TestDogStatsD_TrackSummary(t *testing.T) {
+	conn := listenUDP(t)
+	defer conn.Close()
+
+	client, err := NewDogStatsD(conn.LocalAddr().String(), "", false)
+	require.NoError(t, err)
+
+	client.TrackSummary("section", bucket.NewMetricOperation("foo").WithLabels(map[string]string{"queue": "orders"}), 1.5)
+	require.NoError(t, client.Close())
+
+	assert.Equal(t, []string{"section.foo.-.-:1.5|h|#queue:orders"}, readUDPLines(t, conn))
+}
+
+func TestDogStatsD_TrackStateAdd(t *testing.T) {
+	conn := listenUDP(t)
+	defer conn.Close()
+
+	client, err := NewDogStatsD(conn.LocalAddr().String(), "prefix", false)
+	require.NoError(t, err)
+
+	operation := bucket.NewMetricOperation("jobs").WithLabels(map[string]string{"queue": "orders"})
+	client.TrackStateFloat("section", operation, 1.5)
+	client.TrackStateAdd("section", operation, 2)
+	client.TrackStateSub("section", operation, 0.5)
+	require.NoError(t, client.Close())
+
+	// absolute gauge is flushed before relative update is sent to keep updates order
+	assert.Equal(t, []string{"prefix.section.jobs.-.-:1.5|g|#queue:orders"}, readUDPLines(t, conn))
+	assert.Equal(t, []string{"prefix.section.jobs.-.-:+2|g|#queue:orders"}, readUDPLines(t, conn))
+	assert.Equal(t, []string{"prefix.section.jobs.-.-:-0.5|g|#queue:orders"}, readUDPLines(t, conn))
+}
+
+func TestDogStatsD_TrackUnique(t *testing.T) {
+	conn := listenUDP(t)
+	defer conn.Close()
+
+	client, err := NewDogStatsD(conn.LocalAddr().String(), "", false)
+	require.NoError(t, err)
+
+	client.TrackUnique("section", bucket.NewMetricOperation("users").WithLabels(map[string]string{"country": "de"}), "user-1")
+	require.NoError(t, client.Close())
+
+	assert.Equal(t, []string{"section.users.-.-:user-1|s|#country:de"}, readUDPLines(t, conn))
+}
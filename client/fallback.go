@@ -0,0 +1,297 @@
+package client
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hellofresh/stats-go/bucket"
+	"github.com/hellofresh/stats-go/log"
+	"github.com/hellofresh/stats-go/timer"
+)
+
+// DefaultFallbackRetryInterval is a default interval Fallback client retries primary client connection with
+const DefaultFallbackRetryInterval = 30 * time.Second
+
+// Fallback is Client implementation that tracks metrics with fallback client, e.g. log client,
+// while primary client, e.g. statsd client, can not be connected. Primary client connection is retried
+// in background every retry interval, once it succeeds all the metrics are tracked with primary client.
+// Fallback client is closed and replaced with primary one only once all the in-flight tracking calls return.
+// HTTP metric callback and section and operation depth are applied to primary client when it is connected.
+type Fallback struct {
+	sync.RWMutex
+
+	connect       func() (Client, error)
+	active        Client
+	connected     bool
+	retryInterval time.Duration
+	done          chan struct{}
+	wg            sync.WaitGroup
+	closed        bool
+
+	httpMetricCallback bucket.HTTPMetricNameAlterCallback
+	httpRequestSection string
+	operationDepth     int
+}
+
+// NewFallback builds and returns new Fallback instance that tracks metrics with fallback client
+// and calls connect every retry interval until it succeeds
+func NewFallback(connect func() (Client, error), fallback Client, retryInterval time.Duration) *Fallback {
+	if retryInterval <= 0 {
+		retryInterval = DefaultFallbackRetryInterval
+	}
+
+	c := &Fallback{
+		connect:            connect,
+		active:             fallback,
+		retryInterval:      retryInterval,
+		done:               make(chan struct{}),
+		httpRequestSection: bucket.SectionRequest,
+	}
+
+	c.wg.Add(1)
+	go c.retryConnect()
+
+	return c
+}
+
+func (c *Fallback) retryConnect() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.retryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if c.tryConnect() {
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// tryConnect connects primary client and switches to it if succeeded
+func (c *Fallback) tryConnect() bool {
+	primary, err := c.connect()
+	if err != nil {
+		log.Log("Failed to connect primary stats client, using fallback one", nil, err)
+		return false
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	primary.SetHTTPMetricCallback(c.httpMetricCallback)
+	primary.SetHTTPRequestSection(c.httpRequestSection)
+	primary.SetOperationDepth(c.operationDepth)
+
+	if err := c.active.Close(); err != nil {
+		log.Log("An error occurred while closing fallback stats client", nil, err)
+	}
+	c.active = primary
+	c.connected = true
+
+	log.Log("Primary stats client connected", nil, nil)
+
+	return true
+}
+
+// Active returns client metrics are currently tracked with
+func (c *Fallback) Active() Client {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.active
+}
+
+// Connected returns true if primary client is connected and metrics are tracked with it
+func (c *Fallback) Connected() bool {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.connected
+}
+
+// BuildTimer builds timer to track metric timings
+func (c *Fallback) BuildTimer() timer.Timer {
+	return &timer.Memory{}
+}
+
+// Close stops primary client connection retries and closes active client
+func (c *Fallback) Close() error {
+	c.Lock()
+	if c.closed {
+		c.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.Unlock()
+
+	close(c.done)
+	c.wg.Wait()
+
+	c.Lock()
+	defer c.Unlock()
+
+	return c.active.Close()
+}
+
+// TrackRequest tracks HTTP Request stats
+func (c *Fallback) TrackRequest(r *http.Request, t timer.Timer, success bool) Client {
+	c.RLock()
+	defer c.RUnlock()
+
+	c.active.TrackRequest(r, t, success)
+	return c
+}
+
+// TrackOperation tracks custom operation
+func (c *Fallback) TrackOperation(section string, operation *bucket.MetricOperation, t timer.Timer, success bool) Client {
+	c.RLock()
+	defer c.RUnlock()
+
+	c.active.TrackOperation(section, operation, t, success)
+	return c
+}
+
+// TrackOperationN tracks custom operation with n diff
+func (c *Fallback) TrackOperationN(section string, operation *bucket.MetricOperation, t timer.Timer, n int, success bool) Client {
+	c.RLock()
+	defer c.RUnlock()
+
+	c.active.TrackOperationN(section, operation, t, n, success)
+	return c
+}
+
+// TrackMetric tracks custom metric, w/out ok/fail additional sections
+func (c *Fallback) TrackMetric(section string, operation *bucket.MetricOperation) Client {
+	c.RLock()
+	defer c.RUnlock()
+
+	c.active.TrackMetric(section, operation)
+	return c
+}
+
+// TrackMetricN tracks custom metric with n diff, w/out ok/fail additional sections
+func (c *Fallback) TrackMetricN(section string, operation *bucket.MetricOperation, n int) Client {
+	c.RLock()
+	defer c.RUnlock()
+
+	c.active.TrackMetricN(section, operation, n)
+	return c
+}
+
+// TrackState tracks metric absolute value
+func (c *Fallback) TrackState(section string, operation *bucket.MetricOperation, value int) Client {
+	c.RLock()
+	defer c.RUnlock()
+
+	c.active.TrackState(section, operation, value)
+	return c
+}
+
+// TrackStateFloat tracks metric absolute float value
+func (c *Fallback) TrackStateFloat(section string, operation *bucket.MetricOperation, value float64) Client {
+	c.RLock()
+	defer c.RUnlock()
+
+	c.active.TrackStateFloat(section, operation, value)
+	return c
+}
+
+// TrackStateAdd increases metric state by delta
+func (c *Fallback) TrackStateAdd(section string, operation *bucket.MetricOperation, delta float64) Client {
+	c.RLock()
+	defer c.RUnlock()
+
+	c.active.TrackStateAdd(section, operation, delta)
+	return c
+}
+
+// TrackStateSub decreases metric state by delta
+func (c *Fallback) TrackStateSub(section string, operation *bucket.MetricOperation, delta float64) Client {
+	c.RLock()
+	defer c.RUnlock()
+
+	c.active.TrackStateSub(section, operation, delta)
+	return c
+}
+
+// TrackSummary tracks metric value distribution
+func (c *Fallback) TrackSummary(section string, operation *bucket.MetricOperation, value float64) Client {
+	c.RLock()
+	defer c.RUnlock()
+
+	c.active.TrackSummary(section, operation, value)
+	return c
+}
+
+// TrackUnique tracks number of unique values
+func (c *Fallback) TrackUnique(section string, operation *bucket.MetricOperation, value string) Client {
+	c.RLock()
+	defer c.RUnlock()
+
+	c.active.TrackUnique(section, operation, value)
+	return c
+}
+
+// SetHTTPMetricCallback sets callback handler that allows metric operation alteration for HTTP Request
+func (c *Fallback) SetHTTPMetricCallback(callback bucket.HTTPMetricNameAlterCallback) Client {
+	c.Lock()
+	defer c.Unlock()
+
+	c.httpMetricCallback = callback
+	c.active.SetHTTPMetricCallback(callback)
+	return c
+}
+
+// GetHTTPMetricCallback gets callback handler that allows metric operation alteration for HTTP Request
+func (c *Fallback) GetHTTPMetricCallback() bucket.HTTPMetricNameAlterCallback {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.httpMetricCallback
+}
+
+// SetHTTPRequestSection sets metric section for HTTP Request metrics
+func (c *Fallback) SetHTTPRequestSection(section string) Client {
+	c.Lock()
+	defer c.Unlock()
+
+	c.httpRequestSection = section
+	c.active.SetHTTPRequestSection(section)
+	return c
+}
+
+// ResetHTTPRequestSection resets metric section for HTTP Request metrics to default value that is "request"
+func (c *Fallback) ResetHTTPRequestSection() Client {
+	return c.SetHTTPRequestSection(bucket.SectionRequest)
+}
+
+// SetOperationDepth sets number of operations in metric names
+func (c *Fallback) SetOperationDepth(depth int) Client {
+	c.Lock()
+	defer c.Unlock()
+
+	c.operationDepth = depth
+	c.active.SetOperationDepth(depth)
+	return c
+}
+
+// With returns child client that adds given labels to every tracked metric operation
+func (c *Fallback) With(labels map[string]string) Client {
+	return newScoped(c, labels, "")
+}
+
+// WithSection returns child client that prefixes every tracked metric section with the given prefix
+func (c *Fallback) WithSection(prefix string) Client {
+	return newScoped(c, nil, prefix)
+}
+
+// Handler returns metrics endpoint for prometheus backend
+func (c *Fallback) Handler() http.Handler {
+	return c.Active().Handler()
+}
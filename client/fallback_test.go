@@ -0,0 +1,125 @@
+package client
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hellofresh/stats-go/bucket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFallback(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	release := make(chan struct{})
+	primary := NewMemory(false)
+	connect := func() (Client, error) {
+		mu.Lock()
+		attempts++
+		attempt := attempts
+		mu.Unlock()
+
+		if attempt < 3 {
+			return nil, errors.New("connection refused")
+		}
+		// primary client connects only once test is done with fallback one
+		<-release
+		return primary, nil
+	}
+
+	fallback := NewMemory(false)
+	client := NewFallback(connect, fallback, 10*time.Millisecond)
+	client.SetHTTPRequestSection("api")
+	client.SetOperationDepth(4)
+
+	assert.False(t, client.Connected())
+	assert.Equal(t, fallback, client.Active())
+
+	client.TrackMetric("section", bucket.NewMetricOperation("foo"))
+	assert.Equal(t, 1, fallback.CountMetrics["section.foo.-.-.-"])
+
+	close(release)
+	require.Eventually(t, client.Connected, time.Second, 5*time.Millisecond)
+	assert.Equal(t, primary, client.Active())
+	assert.Equal(t, "api", primary.httpRequestSection)
+	assert.Equal(t, 4, primary.operationDepth)
+	mu.Lock()
+	assert.Equal(t, 3, attempts)
+	mu.Unlock()
+	// fallback client is closed on switch
+	assert.Empty(t, fallback.CountMetrics)
+
+	client.TrackMetric("section", bucket.NewMetricOperation("foo"))
+	assert.Equal(t, 1, primary.CountMetrics["section.foo.-.-.-"])
+
+	assert.NoError(t, client.Close())
+	// second close is no-op
+	assert.NoError(t, client.Close())
+}
+
+// blockingClient is Memory client that blocks metric tracking until released
+// and records if metric was tracked after client was closed
+type blockingClient struct {
+	*Memory
+
+	entered      chan struct{}
+	release      chan struct{}
+	closed       bool
+	trackedAfter bool
+}
+
+func (c *blockingClient) TrackMetric(section string, operation *bucket.MetricOperation) Client {
+	close(c.entered)
+	<-c.release
+	c.trackedAfter = c.closed
+	return c.Memory.TrackMetric(section, operation)
+}
+
+func (c *blockingClient) Close() error {
+	c.closed = true
+	return c.Memory.Close()
+}
+
+func TestFallback_SwitchWaitsForTracking(t *testing.T) {
+	fallback := &blockingClient{Memory: NewMemory(false), entered: make(chan struct{}), release: make(chan struct{})}
+	client := NewFallback(func() (Client, error) {
+		return nil, errors.New("connection refused")
+	}, fallback, time.Hour)
+	client.connect = func() (Client, error) {
+		return NewMemory(false), nil
+	}
+
+	tracked := make(chan struct{})
+	go func() {
+		defer close(tracked)
+		client.TrackMetric("section", bucket.NewMetricOperation("foo"))
+	}()
+	<-fallback.entered
+
+	switched := make(chan bool)
+	go func() {
+		switched <- client.tryConnect()
+	}()
+
+	close(fallback.release)
+	<-tracked
+	assert.True(t, <-switched)
+	assert.False(t, fallback.trackedAfter)
+	assert.True(t, fallback.closed)
+	assert.True(t, client.Connected())
+
+	assert.NoError(t, client.Close())
+}
+
+func TestFallback_Close(t *testing.T) {
+	connect := func() (Client, error) {
+		return nil, errors.New("connection refused")
+	}
+
+	client := NewFallback(connect, NewNoop(), time.Hour)
+	assert.NoError(t, client.Close())
+	assert.False(t, client.Connected())
+}
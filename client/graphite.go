@@ -0,0 +1,396 @@
+package client
+
+import (
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hellofresh/stats-go/bucket"
+	"github.com/hellofresh/stats-go/log"
+	"github.com/hellofresh/stats-go/timer"
+)
+
+const (
+	// DefaultGraphiteFlushInterval is a default interval Graphite client sends aggregated metrics with
+	DefaultGraphiteFlushInterval = 10 * time.Second
+
+	graphiteMaxPacketSize = 64 * 1024
+	graphiteDialTimeout   = 5 * time.Second
+)
+
+// Graphite is Client implementation for Graphite plaintext protocol over TCP.
+// Metric names are the same as for StatsD client. As carbon does not aggregate values, metrics are aggregated
+// in memory and sent every flush interval: counters are summed, states keep the last value and relative
+// state updates are applied to the last known value, timings
+// are sent as "<metric>.count", "<metric>.mean", "<metric>.lower" and "<metric>.upper" in milliseconds
+// summaries are sent with the same suffixes as observed values and unique values are sent as their count.
+type Graphite struct {
+	sync.Mutex
+	httpMetricCallback bucket.HTTPMetricNameAlterCallback
+	httpRequestSection string
+	operationDepth     int
+	unicode            bool
+
+	prefix string
+	conn   *reconnectingConn
+	writer *lineWriter
+	done   chan struct{}
+	wg     sync.WaitGroup
+	closed bool
+
+	metricsMu sync.Mutex
+	counters  map[string]int
+	states    map[string]float64
+	gauges    map[string]float64
+	timers    map[string][]time.Duration
+	summaries map[string][]float64
+	uniques   map[string]map[string]struct{}
+}
+
+// NewGraphite builds and returns new Graphite instance
+func NewGraphite(addr string, prefix string, flushInterval time.Duration, unicode bool) (*Graphite, error) {
+	log.Log("Trying to connect to graphite instance", map[string]interface{}{
+		"addr":   addr,
+		"prefix": prefix,
+	}, nil)
+
+	conn := newReconnectingConn(func() (net.Conn, error) {
+		return net.DialTimeout("tcp", addr, graphiteDialTimeout)
+	})
+	if err := conn.Connect(); err != nil {
+		log.Log("An error occurred while connecting to graphite", map[string]interface{}{
+			"addr":   addr,
+			"prefix": prefix,
+		}, err)
+		return nil, err
+	}
+
+	if prefix != "" {
+		prefix = strings.TrimSuffix(prefix, ".") + "."
+	}
+
+	if flushInterval <= 0 {
+		flushInterval = DefaultGraphiteFlushInterval
+	}
+
+	client := &Graphite{
+		unicode: unicode,
+		prefix:  prefix,
+		conn:    conn,
+		writer:  newLineWriter(conn.Send, graphiteMaxPacketSize, 0),
+		done:    make(chan struct{}),
+	}
+	client.ResetHTTPRequestSection()
+	client.resetMetrics()
+	client.gauges = map[string]float64{}
+
+	client.wg.Add(1)
+	go client.flushPeriodically(flushInterval)
+
+	return client, nil
+}
+
+func (c *Graphite) resetMetrics() {
+	c.counters = map[string]int{}
+	c.states = map[string]float64{}
+	c.timers = map[string][]time.Duration{}
+	c.summaries = map[string][]float64{}
+	c.uniques = map[string]map[string]struct{}{}
+}
+
+func (c *Graphite) flushPeriodically(flushInterval time.Duration) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.Flush()
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// Flush sends all metrics aggregated since the previous flush
+func (c *Graphite) Flush() {
+	c.metricsMu.Lock()
+	counters, states, timers, summaries, uniques := c.counters, c.states, c.timers, c.summaries, c.uniques
+	c.resetMetrics()
+	c.metricsMu.Unlock()
+
+	timestamp := " " + strconv.FormatInt(time.Now().Unix(), 10) + "\n"
+	write := func(metric string, value string) {
+		c.writer.Write([]byte(c.prefix + metric + " " + value + timestamp))
+	}
+
+	for metric, value := range counters {
+		write(metric, strconv.Itoa(value))
+	}
+	for metric, value := range states {
+		write(metric, formatFloat(value))
+	}
+	for metric, values := range timers {
+		sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+
+		var sum time.Duration
+		for _, v := range values {
+			sum += v
+		}
+
+		write(metric+".count", strconv.Itoa(len(values)))
+		write(metric+".mean", formatMilliseconds(sum/time.Duration(len(values))))
+		write(metric+".lower", formatMilliseconds(values[0]))
+		write(metric+".upper", formatMilliseconds(values[len(values)-1]))
+	}
+	for metric, values := range summaries {
+		sort.Float64s(values)
+
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+
+		write(metric+".count", strconv.Itoa(len(values)))
+		write(metric+".mean", formatFloat(sum/float64(len(values))))
+		write(metric+".lower", formatFloat(values[0]))
+		write(metric+".upper", formatFloat(values[len(values)-1]))
+	}
+	for metric, values := range uniques {
+		write(metric, strconv.Itoa(len(values)))
+	}
+
+	c.writer.Flush()
+}
+
+func formatMilliseconds(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', -1, 64)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func (c *Graphite) increment(n int, metrics ...string) {
+	c.metricsMu.Lock()
+	defer c.metricsMu.Unlock()
+
+	for _, metric := range metrics {
+		c.counters[metric] += n
+	}
+}
+
+func (c *Graphite) timing(metric string, t timer.Timer) {
+	if nil == t {
+		return
+	}
+
+	elapsed := t.Finish()
+
+	c.metricsMu.Lock()
+	defer c.metricsMu.Unlock()
+
+	c.timers[metric] = append(c.timers[metric], elapsed)
+}
+
+// BuildTimer builds timer to track metric timings
+func (c *Graphite) BuildTimer() timer.Timer {
+	return &timer.Memory{}
+}
+
+// Close sends aggregated metrics and closes graphite connection
+func (c *Graphite) Close() error {
+	c.Lock()
+	if c.closed {
+		c.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.Unlock()
+
+	close(c.done)
+	c.wg.Wait()
+
+	c.Flush()
+	c.writer.Close()
+
+	return c.conn.Close()
+}
+
+// TrackRequest tracks HTTP Request stats
+func (c *Graphite) TrackRequest(r *http.Request, t timer.Timer, success bool) Client {
+	b := bucket.NewHTTPRequestWithDepth(c.httpRequestSection, r, success, c.httpMetricCallback, c.unicode, c.operationDepth)
+
+	c.timing(b.Metric(), t)
+	c.increment(1, b.Metric(), b.MetricWithSuffix(), b.MetricTotal(), b.MetricTotalWithSuffix())
+
+	return c
+}
+
+// TrackOperation tracks custom operation
+func (c *Graphite) TrackOperation(section string, operation *bucket.MetricOperation, t timer.Timer, success bool) Client {
+	return c.TrackOperationN(section, operation, t, 1, success)
+}
+
+// TrackOperationN tracks custom operation with n diff
+func (c *Graphite) TrackOperationN(section string, operation *bucket.MetricOperation, t timer.Timer, n int, success bool) Client {
+	b := bucket.NewPlainWithDepth(section, operation, success, c.unicode, c.operationDepth)
+
+	c.timing(b.MetricWithSuffix(), t)
+	c.increment(n, b.Metric(), b.MetricWithSuffix(), b.MetricTotal(), b.MetricTotalWithSuffix())
+
+	return c
+}
+
+// TrackMetric tracks custom metric, w/out ok/fail additional sections
+func (c *Graphite) TrackMetric(section string, operation *bucket.MetricOperation) Client {
+	return c.TrackMetricN(section, operation, 1)
+}
+
+// TrackMetricN tracks custom metric with n diff, w/out ok/fail additional sections
+func (c *Graphite) TrackMetricN(section string, operation *bucket.MetricOperation, n int) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
+
+	c.increment(n, b.Metric(), b.MetricTotal())
+
+	return c
+}
+
+// setState sets the last known state value that is sent on the next flush
+func (c *Graphite) setState(metric string, value float64) {
+	c.metricsMu.Lock()
+	defer c.metricsMu.Unlock()
+
+	c.gauges[metric] = value
+	c.states[metric] = value
+}
+
+// addState applies delta to the last known state value that is sent on the next flush
+func (c *Graphite) addState(metric string, delta float64) {
+	c.metricsMu.Lock()
+	defer c.metricsMu.Unlock()
+
+	c.gauges[metric] += delta
+	c.states[metric] = c.gauges[metric]
+}
+
+// TrackState tracks metric absolute value
+func (c *Graphite) TrackState(section string, operation *bucket.MetricOperation, value int) Client {
+	return c.TrackStateFloat(section, operation, float64(value))
+}
+
+// TrackStateFloat tracks metric absolute float value
+func (c *Graphite) TrackStateFloat(section string, operation *bucket.MetricOperation, value float64) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
+
+	c.setState(b.Metric(), value)
+
+	return c
+}
+
+// TrackStateAdd increases metric state by delta
+func (c *Graphite) TrackStateAdd(section string, operation *bucket.MetricOperation, delta float64) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
+
+	c.addState(b.Metric(), delta)
+
+	return c
+}
+
+// TrackStateSub decreases metric state by delta
+func (c *Graphite) TrackStateSub(section string, operation *bucket.MetricOperation, delta float64) Client {
+	return c.TrackStateAdd(section, operation, -delta)
+}
+
+// TrackSummary tracks metric value distribution
+func (c *Graphite) TrackSummary(section string, operation *bucket.MetricOperation, value float64) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
+
+	c.metricsMu.Lock()
+	defer c.metricsMu.Unlock()
+
+	c.summaries[b.Metric()] = append(c.summaries[b.Metric()], value)
+
+	return c
+}
+
+// TrackUnique tracks number of unique values per flush interval
+func (c *Graphite) TrackUnique(section string, operation *bucket.MetricOperation, value string) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
+
+	c.metricsMu.Lock()
+	defer c.metricsMu.Unlock()
+
+	values, ok := c.uniques[b.Metric()]
+	if !ok {
+		values = make(map[string]struct{})
+		c.uniques[b.Metric()] = values
+	}
+	values[value] = struct{}{}
+
+	return c
+}
+
+// SetHTTPMetricCallback sets callback handler that allows metric operation alteration for HTTP Request
+func (c *Graphite) SetHTTPMetricCallback(callback bucket.HTTPMetricNameAlterCallback) Client {
+	c.Lock()
+	defer c.Unlock()
+
+	c.httpMetricCallback = callback
+	return c
+}
+
+// GetHTTPMetricCallback gets callback handler that allows metric operation alteration for HTTP Request
+func (c *Graphite) GetHTTPMetricCallback() bucket.HTTPMetricNameAlterCallback {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.httpMetricCallback
+}
+
+// SetHTTPRequestSection sets metric section for HTTP Request metrics
+func (c *Graphite) SetHTTPRequestSection(section string) Client {
+	c.Lock()
+	defer c.Unlock()
+
+	c.httpRequestSection = section
+	return c
+}
+
+// ResetHTTPRequestSection resets metric section for HTTP Request metrics to default value that is "request"
+func (c *Graphite) ResetHTTPRequestSection() Client {
+	return c.SetHTTPRequestSection(bucket.SectionRequest)
+}
+
+// SetOperationDepth sets number of operations in metric names, shorter operations are padded with placeholders
+// and longer ones are truncated, zero value means bucket.MetricOperationsMaxLength
+func (c *Graphite) SetOperationDepth(depth int) Client {
+	c.Lock()
+	defer c.Unlock()
+
+	c.operationDepth = depth
+	return c
+}
+
+// With returns child client that adds given labels to every tracked metric operation
+func (c *Graphite) With(labels map[string]string) Client {
+	return newScoped(c, labels, "")
+}
+
+// WithSection returns child client that prefixes every tracked metric section with the given prefix
+func (c *Graphite) WithSection(prefix string) Client {
+	return newScoped(c, nil, prefix)
+}
+
+// Handler returns metrics endpoint for prometheus backend
+func (c *Graphite) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hellofresh/stats-go/bucket"
+	"github.com/hellofresh/stats-go/timer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphite(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	received := make(chan string)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		b, _ := ioutil.ReadAll(conn)
+		received <- string(b)
+	}()
+
+	client, err := NewGraphite(listener.Addr().String(), "app", time.Hour, false)
+	require.NoError(t, err)
+
+	operation := bucket.NewMetricOperation("orders", "create")
+	client.TrackOperation("ordering", operation, timer.NewDuration(10*time.Millisecond), true)
+	client.TrackOperationN("ordering", operation, timer.NewDuration(30*time.Millisecond), 2, true)
+	client.TrackState("ordering", bucket.NewMetricOperation("orders", "pending"), 42)
+	client.TrackSummary("ordering", bucket.NewMetricOperation("orders", "size"), 3)
+	client.TrackSummary("ordering", bucket.NewMetricOperation("orders", "size"), 1.5)
+	client.TrackStateFloat("ordering", bucket.NewMetricOperation("orders", "inflight"), 1.5)
+	client.TrackStateAdd("ordering", bucket.NewMetricOperation("orders", "inflight"), 2)
+	client.TrackStateSub("ordering", bucket.NewMetricOperation("orders", "inflight"), 0.5)
+	client.TrackUnique("ordering", bucket.NewMetricOperation("customers"), "c1")
+	client.TrackUnique("ordering", bucket.NewMetricOperation("customers"), "c2")
+	client.TrackUnique("ordering", bucket.NewMetricOperation("customers"), "c1")
+	require.NoError(t, client.Close())
+	// second close is no-op
+	require.NoError(t, client.Close())
+
+	var lines []string
+	for _, line := range strings.Split(<-received, "\n") {
+		if line == "" {
+			continue
+		}
+		parts := strings.Split(line, " ")
+		require.Equal(t, 3, len(parts), line)
+		lines = append(lines, parts[0]+" "+parts[1])
+	}
+	sort.Strings(lines)
+
+	assert.Equal(t, []string{
+		"app.ordering-ok.orders.create.- 3",
+		"app.ordering-ok.orders.create.-.count 2",
+		"app.ordering-ok.orders.create.-.lower 10",
+		"app.ordering-ok.orders.create.-.mean 20",
+		"app.ordering-ok.orders.create.-.upper 30",
+		"app.ordering.customers.-.- 2",
+		"app.ordering.orders.create.- 3",
+		"app.ordering.orders.inflight.- 3",
+		"app.ordering.orders.pending.- 42",
+		"app.ordering.orders.size.-.count 2",
+		"app.ordering.orders.size.-.lower 1.5",
+		"app.ordering.orders.size.-.mean 2.25",
+		"app.ordering.orders.size.-.upper 3",
+		"app.total.ordering 3",
+		"app.total.ordering-ok 3",
+	}, lines)
+}
+
+func TestReconnectingConn_Backoff(t *testing.T) {
+	now := time.Now()
+	dials := 0
+	errDial := errors.New("dial error")
+
+	conn := newReconnectingConn(func() (net.Conn, error) {
+		dials++
+		return nil, errDial
+	})
+	conn.now = func() time.Time { return now }
+
+	assert.Equal(t, errDial, conn.Send([]byte("foo")))
+	assert.Equal(t, ErrReconnectBackoff, conn.Send([]byte("foo")))
+	assert.Equal(t, 1, dials)
+
+	now = now.Add(reconnectMinBackoff)
+	assert.Equal(t, errDial, conn.Send([]byte("foo")))
+	assert.Equal(t, 2, dials)
+	assert.Equal(t, 2*reconnectMinBackoff, conn.backoff)
+
+	now = now.Add(reconnectMinBackoff)
+	assert.Equal(t, ErrReconnectBackoff, conn.Send([]byte("foo")))
+	assert.Equal(t, 2, dials)
+}
+
+func TestReconnectingConn_Reconnect(t *testing.T) {
+	var conns []net.Conn
+	conn := newReconnectingConn(func() (net.Conn, error) {
+		client, server := net.Pipe()
+		conns = append(conns, server)
+		go ioutil.ReadAll(server)
+
+		return client, nil
+	})
+
+	require.NoError(t, conn.Send([]byte("foo")))
+	conns[0].Close()
+
+	require.NoError(t, conn.Send([]byte("bar")))
+	assert.Equal(t, 2, len(conns))
+	assert.NoError(t, conn.Close())
+
+	// closed connection is not re-established
+	assert.Equal(t, ErrConnClosed, conn.Send([]byte("baz")))
+	assert.Equal(t, ErrConnClosed, conn.Connect())
+	assert.Equal(t, 2, len(conns))
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+)
+
+// hyperLogLogPrecision is a number of hash bits used for register index, 2^12 registers give ~1.6% standard error
+const hyperLogLogPrecision = 12
+
+// hyperLogLog is HyperLogLog cardinality estimator, see http://algo.inria.fr/flajolet/Publications/FlFuGaMe07.pdf
+type hyperLogLog struct {
+	registers []uint8
+}
+
+func newHyperLogLog() *hyperLogLog {
+	return &hyperLogLog{registers: make([]uint8, 1<<hyperLogLogPrecision)}
+}
+
+// hash64 returns 64-bit FNV-1a hash with additional bits mixing, as FNV high bits are not uniform enough for short values
+func hash64(value string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(value))
+	x := h.Sum64()
+
+	// splitmix64 finalizer
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+
+	return x
+}
+
+// Add adds value to the set, returns true if estimate may have changed
+func (h *hyperLogLog) Add(value string) bool {
+	x := hash64(value)
+	idx := x >> (64 - hyperLogLogPrecision)
+	rank := uint8(bits.LeadingZeros64(x<<hyperLogLogPrecision|1<<(hyperLogLogPrecision-1)) + 1)
+
+	if rank > h.registers[idx] {
+		h.registers[idx] = rank
+		return true
+	}
+
+	return false
+}
+
+// Estimate returns estimated number of unique values added to the set
+func (h *hyperLogLog) Estimate() float64 {
+	m := float64(len(h.registers))
+	alpha := 0.7213 / (1 + 1.079/m)
+
+	var sum float64
+	var zeros int
+	for _, r := range h.registers {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	estimate := alpha * m * m / sum
+	// small range correction
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+
+	return estimate
+}
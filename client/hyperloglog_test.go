@@ -0,0 +1,27 @@
+package client
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	h := newHyperLogLog()
+	assert.Equal(t, float64(0), h.Estimate())
+
+	for i := 0; i < 10; i++ {
+		h.Add("user-1")
+	}
+	assert.InDelta(t, 1, h.Estimate(), 0.01)
+
+	for _, n := range []int{100, 10000, 200000} {
+		h := newHyperLogLog()
+		for i := 0; i < n; i++ {
+			h.Add("user-" + strconv.Itoa(i))
+			h.Add("user-" + strconv.Itoa(i))
+		}
+		assert.InEpsilon(t, n, h.Estimate(), 0.05, "cardinality %d", n)
+	}
+}
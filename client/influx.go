@@ -0,0 +1,335 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hellofresh/stats-go/bucket"
+	"github.com/hellofresh/stats-go/internal/labelset"
+	"github.com/hellofresh/stats-go/log"
+	"github.com/hellofresh/stats-go/timer"
+)
+
+const (
+	influxFieldCount    = "count"
+	influxFieldDuration = "duration"
+	influxFieldValue    = "value"
+	influxFieldSummary  = "summary"
+	influxFieldGauge    = "gauge"
+	influxFieldDelta    = "delta"
+	influxFieldUnique   = "unique"
+
+	influxTagSuccess = "success"
+	influxTagMethod  = "method"
+
+	influxHTTPMaxPacketSize = 64 * 1024
+	influxHTTPTimeout       = 5 * time.Second
+)
+
+var (
+	influxMeasurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	influxTagEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+	influxStringEscaper      = strings.NewReplacer(`"`, `\"`, `\`, `\\`)
+)
+
+// Influx is Client implementation for InfluxDB line protocol, e.g. for InfluxDB or Telegraf listeners.
+// Every metric is written as a point to "<prefix>_<section>" measurement, operations are written as
+// "operation0", "operation1", "operation2" tags and MetricOperation.Labels are written as tags as well.
+// Counters are written to "count" field, timings in milliseconds to "duration" field, states to "value" field,
+// float states to "gauge" field, relative state updates to "delta" field, that should be summed up in queries,
+// summary observations to "summary" float field and unique values to "unique" string field,
+// that should be counted with COUNT(DISTINCT()) in queries.
+type Influx struct {
+	sync.Mutex
+	writer             *lineWriter
+	closer             func() error
+	prefix             string
+	httpMetricCallback bucket.HTTPMetricNameAlterCallback
+	httpRequestSection string
+	operationDepth     int
+	unicode            bool
+}
+
+// NewInfluxUDP builds and returns new Influx instance that writes points over UDP
+func NewInfluxUDP(addr string, prefix string, unicode bool) (*Influx, error) {
+	log.Log("Trying to connect to influx UDP listener", map[string]interface{}{
+		"addr":   addr,
+		"prefix": prefix,
+	}, nil)
+
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		log.Log("An error occurred while connecting to influx UDP listener", map[string]interface{}{
+			"addr":   addr,
+			"prefix": prefix,
+		}, err)
+		return nil, err
+	}
+
+	send := func(b []byte) error {
+		_, err := conn.Write(b)
+		return err
+	}
+
+	return newInflux(newLineWriter(send, defaultMaxPacketSize, defaultFlushPeriod), conn.Close, prefix, unicode), nil
+}
+
+// NewInfluxHTTP builds and returns new Influx instance that writes points to HTTP write endpoint,
+// addr is an HTTP base address of InfluxDB or Telegraf listener, e.g. "http://localhost:8086"
+func NewInfluxHTTP(addr string, database string, prefix string, unicode bool) (*Influx, error) {
+	writeURL, err := url.Parse(strings.TrimRight(addr, "/") + "/write")
+	if err != nil {
+		return nil, err
+	}
+
+	query := writeURL.Query()
+	if database != "" {
+		query.Set("db", database)
+	}
+	query.Set("precision", "ns")
+	writeURL.RawQuery = query.Encode()
+
+	httpClient := &http.Client{Timeout: influxHTTPTimeout}
+	send := func(b []byte) error {
+		resp, err := httpClient.Post(writeURL.String(), "text/plain; charset=utf-8", bytes.NewReader(b))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+			return fmt.Errorf("influx write endpoint responded with unexpected status code %d", resp.StatusCode)
+		}
+		return nil
+	}
+
+	return newInflux(newLineWriter(send, influxHTTPMaxPacketSize, time.Second), nil, prefix, unicode), nil
+}
+
+func newInflux(writer *lineWriter, closer func() error, prefix string, unicode bool) *Influx {
+	client := &Influx{writer: writer, closer: closer, prefix: prefix, unicode: unicode}
+	client.ResetHTTPRequestSection()
+
+	return client
+}
+
+// measurement builds measurement name for a section
+func (c *Influx) measurement(section string) string {
+	measurement := bucket.SanitizeMetricName(section, c.unicode)
+	if c.prefix != "" {
+		measurement = c.prefix + "_" + measurement
+	}
+
+	return measurement
+}
+
+// write builds single point in line protocol format and writes it to the buffer
+func (c *Influx) write(section string, operations []string, labels map[string]string, fields map[string]string) {
+	tags := make(map[string]string, len(labels)+len(operations))
+	for k, v := range labels {
+		tags[k] = v
+	}
+	for i, operation := range operations {
+		tags["operation"+strconv.Itoa(i)] = operation
+	}
+
+	var line bytes.Buffer
+	line.WriteString(influxMeasurementEscaper.Replace(c.measurement(section)))
+
+	for _, k := range labelset.Keys(tags) {
+		// line protocol does not allow empty tag values
+		if tags[k] == "" {
+			continue
+		}
+		line.WriteByte(',')
+		line.WriteString(influxTagEscaper.Replace(k))
+		line.WriteByte('=')
+		line.WriteString(influxTagEscaper.Replace(tags[k]))
+	}
+
+	for i, k := range labelset.Keys(fields) {
+		if i == 0 {
+			line.WriteByte(' ')
+		} else {
+			line.WriteByte(',')
+		}
+		line.WriteString(influxTagEscaper.Replace(k))
+		line.WriteByte('=')
+		line.WriteString(fields[k])
+	}
+
+	line.WriteByte(' ')
+	line.WriteString(strconv.FormatInt(time.Now().UnixNano(), 10))
+	line.WriteByte('\n')
+
+	c.writer.Write(line.Bytes())
+}
+
+// counterFields builds fields for counter point with optional timing
+func (c *Influx) counterFields(n int, t timer.Timer) map[string]string {
+	fields := map[string]string{influxFieldCount: strconv.Itoa(n) + "i"}
+	if nil != t {
+		fields[influxFieldDuration] = strconv.FormatFloat(float64(t.Finish())/float64(time.Millisecond), 'f', -1, 64)
+	}
+
+	return fields
+}
+
+// BuildTimer builds timer to track metric timings
+func (c *Influx) BuildTimer() timer.Timer {
+	return &timer.Memory{}
+}
+
+// Close flushes buffered points and closes underlying connection if any
+func (c *Influx) Close() error {
+	c.writer.Close()
+	if c.closer != nil {
+		return c.closer()
+	}
+	return nil
+}
+
+// TrackRequest tracks HTTP Request stats
+func (c *Influx) TrackRequest(r *http.Request, t timer.Timer, success bool) Client {
+	operations := bucket.BuildHTTPRequestMetricOperationWithDepth(r, c.httpMetricCallback, c.operationDepth).Operations()
+	labels := map[string]string{influxTagSuccess: strconv.FormatBool(success), influxTagMethod: operations[0]}
+
+	c.write(c.httpRequestSection, operations[1:], labels, c.counterFields(1, t))
+
+	return c
+}
+
+// TrackOperation tracks custom operation
+func (c *Influx) TrackOperation(section string, operation *bucket.MetricOperation, t timer.Timer, success bool) Client {
+	return c.TrackOperationN(section, operation, t, 1, success)
+}
+
+// TrackOperationN tracks custom operation with n diff
+func (c *Influx) TrackOperationN(section string, operation *bucket.MetricOperation, t timer.Timer, n int, success bool) Client {
+	labels := make(map[string]string, len(operation.Labels)+1)
+	for k, v := range operation.Labels {
+		labels[k] = v
+	}
+	labels[influxTagSuccess] = strconv.FormatBool(success)
+
+	c.write(section, operation.OperationsWithDepth(c.operationDepth), labels, c.counterFields(n, t))
+
+	return c
+}
+
+// TrackMetric tracks custom metric, w/out ok/fail additional sections
+func (c *Influx) TrackMetric(section string, operation *bucket.MetricOperation) Client {
+	return c.TrackMetricN(section, operation, 1)
+}
+
+// TrackMetricN tracks custom metric with n diff, w/out ok/fail additional sections
+func (c *Influx) TrackMetricN(section string, operation *bucket.MetricOperation, n int) Client {
+	c.write(section, operation.OperationsWithDepth(c.operationDepth), operation.Labels, c.counterFields(n, nil))
+
+	return c
+}
+
+// TrackState tracks metric absolute value
+func (c *Influx) TrackState(section string, operation *bucket.MetricOperation, value int) Client {
+	c.write(section, operation.OperationsWithDepth(c.operationDepth), operation.Labels, map[string]string{influxFieldValue: strconv.Itoa(value) + "i"})
+
+	return c
+}
+
+// TrackStateFloat tracks metric absolute float value
+func (c *Influx) TrackStateFloat(section string, operation *bucket.MetricOperation, value float64) Client {
+	c.write(section, operation.OperationsWithDepth(c.operationDepth), operation.Labels, map[string]string{influxFieldGauge: strconv.FormatFloat(value, 'f', -1, 64)})
+
+	return c
+}
+
+// TrackStateAdd increases metric state by delta
+func (c *Influx) TrackStateAdd(section string, operation *bucket.MetricOperation, delta float64) Client {
+	c.write(section, operation.OperationsWithDepth(c.operationDepth), operation.Labels, map[string]string{influxFieldDelta: strconv.FormatFloat(delta, 'f', -1, 64)})
+
+	return c
+}
+
+// TrackStateSub decreases metric state by delta
+func (c *Influx) TrackStateSub(section string, operation *bucket.MetricOperation, delta float64) Client {
+	return c.TrackStateAdd(section, operation, -delta)
+}
+
+// TrackSummary tracks metric value distribution, every observation is written as a separate point
+func (c *Influx) TrackSummary(section string, operation *bucket.MetricOperation, value float64) Client {
+	c.write(section, operation.OperationsWithDepth(c.operationDepth), operation.Labels, map[string]string{influxFieldSummary: strconv.FormatFloat(value, 'f', -1, 64)})
+
+	return c
+}
+
+// TrackUnique tracks number of unique values
+func (c *Influx) TrackUnique(section string, operation *bucket.MetricOperation, value string) Client {
+	c.write(section, operation.OperationsWithDepth(c.operationDepth), operation.Labels, map[string]string{influxFieldUnique: `"` + influxStringEscaper.Replace(value) + `"`})
+
+	return c
+}
+
+// SetHTTPMetricCallback sets callback handler that allows metric operation alteration for HTTP Request
+func (c *Influx) SetHTTPMetricCallback(callback bucket.HTTPMetricNameAlterCallback) Client {
+	c.Lock()
+	defer c.Unlock()
+
+	c.httpMetricCallback = callback
+	return c
+}
+
+// GetHTTPMetricCallback gets callback handler that allows metric operation alteration for HTTP Request
+func (c *Influx) GetHTTPMetricCallback() bucket.HTTPMetricNameAlterCallback {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.httpMetricCallback
+}
+
+// SetHTTPRequestSection sets metric section for HTTP Request metrics
+func (c *Influx) SetHTTPRequestSection(section string) Client {
+	c.Lock()
+	defer c.Unlock()
+
+	c.httpRequestSection = section
+	return c
+}
+
+// ResetHTTPRequestSection resets metric section for HTTP Request metrics to default value that is "request"
+func (c *Influx) ResetHTTPRequestSection() Client {
+	return c.SetHTTPRequestSection(bucket.SectionRequest)
+}
+
+// SetOperationDepth sets number of operations in metric names, shorter operations are padded with placeholders
+// and longer ones are truncated, zero value means bucket.MetricOperationsMaxLength
+func (c *Influx) SetOperationDepth(depth int) Client {
+	c.Lock()
+	defer c.Unlock()
+
+	c.operationDepth = depth
+	return c
+}
+
+// With returns child client that adds given labels to every tracked metric operation
+func (c *Influx) With(labels map[string]string) Client {
+	return newScoped(c, labels, "")
+}
+
+// WithSection returns child client that prefixes every tracked metric section with the given prefix
+func (c *Influx) WithSection(prefix string) Client {
+	return newScoped(c, nil, prefix)
+}
+
+// Handler returns metrics endpoint for prometheus backend
+func (c *Influx) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+}
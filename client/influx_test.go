@@ -0,0 +1,138 @@
+package client
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hellofresh/stats-go/bucket"
+	"github.com/hellofresh/stats-go/timer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stripTimestamps removes timestamps from line protocol points
+func stripTimestamps(t *testing.T, lines []string) []string {
+	var result []string
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		idx := strings.LastIndex(line, " ")
+		require.True(t, idx > 0, line)
+		result = append(result, line[:idx])
+	}
+
+	return result
+}
+
+func TestInflux_UDP(t *testing.T) {
+	conn := listenUDP(t)
+	defer conn.Close()
+
+	client, err := NewInfluxUDP(conn.LocalAddr().String(), "app", false)
+	require.NoError(t, err)
+
+	operation := bucket.NewMetricOperation("orders", "create").WithLabels(map[string]string{"country": "de at"})
+	client.TrackOperationN("ordering", operation, timer.NewDuration(1500*time.Microsecond), 2, true)
+	client.TrackMetric("ordering", bucket.NewMetricOperation("orders"))
+	client.TrackState("ordering", bucket.NewMetricOperation("orders", "pending"), 42)
+	client.TrackSummary("ordering", bucket.NewMetricOperation("orders", "size"), 2.5)
+	client.TrackStateFloat("ordering", bucket.NewMetricOperation("orders", "ratio"), 0.75)
+	client.TrackStateSub("ordering", bucket.NewMetricOperation("orders", "inflight"), 1)
+	client.TrackUnique("ordering", bucket.NewMetricOperation("customers"), `c"1`)
+	require.NoError(t, client.Close())
+
+	assert.Equal(t, []string{
+		`app_ordering,country=de\ at,operation0=orders,operation1=create,operation2=-,success=true count=2i,duration=1.5`,
+		`app_ordering,operation0=orders,operation1=-,operation2=- count=1i`,
+		`app_ordering,operation0=orders,operation1=pending,operation2=- value=42i`,
+		`app_ordering,operation0=orders,operation1=size,operation2=- summary=2.5`,
+		`app_ordering,operation0=orders,operation1=ratio,operation2=- gauge=0.75`,
+		`app_ordering,operation0=orders,operation1=inflight,operation2=- delta=-1`,
+		`app_ordering,operation0=customers,operation1=-,operation2=- unique="c\"1"`,
+	}, stripTimestamps(t, readUDPLines(t, conn)))
+}
+
+func TestInflux_HTTP(t *testing.T) {
+	var (
+		query url.Values
+		body  string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/write", r.URL.Path)
+		query = r.URL.Query()
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client, err := NewInfluxHTTP(server.URL, "telegraf", "", false)
+	require.NoError(t, err)
+
+	r := &http.Request{Method: http.MethodGet, URL: &url.URL{Path: "/users/13"}}
+	client.TrackRequest(r, nil, false)
+	require.NoError(t, client.Close())
+
+	assert.Equal(t, "telegraf", query.Get("db"))
+	assert.Equal(t, "ns", query.Get("precision"))
+	assert.Equal(t, []string{
+		`request,method=get,operation0=users,operation1=13,success=false count=1i`,
+	}, stripTimestamps(t, strings.Split(body, "\n")))
+}
+
+func TestLineWriter_Batching(t *testing.T) {
+	var packets []string
+	w := newLineWriter(func(b []byte) error {
+		packets = append(packets, string(b))
+		return nil
+	}, 10, 0)
+
+	w.Write([]byte("abcd\n"))
+	w.Write([]byte("efgh\n"))
+	w.Write([]byte("ijklmnopqrst\n"))
+	w.Write([]byte("uv\n"))
+	w.Close()
+	w.Write([]byte("wx\n"))
+	w.Flush()
+
+	assert.Equal(t, []string{"abcd\nefgh\n", "ijklmnopqrst\n", "uv\n"}, packets)
+}
+
+func TestLineWriter_WriteDoesNotWaitForSend(t *testing.T) {
+	unblock := make(chan struct{})
+	var mu sync.Mutex
+	var packets []string
+	w := newLineWriter(func(b []byte) error {
+		<-unblock
+		mu.Lock()
+		defer mu.Unlock()
+		packets = append(packets, string(b))
+		return nil
+	}, 10, 0)
+
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		for i := 0; i < 10; i++ {
+			w.Write([]byte("abcdefgh\n"))
+		}
+	}()
+
+	select {
+	case <-written:
+	case <-time.After(time.Second):
+		t.Fatal("write is blocked by slow send")
+	}
+
+	close(unblock)
+	w.Close()
+
+	assert.Len(t, packets, 10)
+}
@@ -0,0 +1,182 @@
+package client
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/hellofresh/stats-go/log"
+)
+
+const (
+	// defaultFlushPeriod is a default period lineWriter flushes buffered lines with
+	defaultFlushPeriod = 100 * time.Millisecond
+	// defaultMaxPacketSize is a default max packet size for datagram based transports,
+	// Ethernet MTU - IPv6 Header - TCP Header = 1500 - 40 - 20 = 1440
+	defaultMaxPacketSize = 1440
+	// lineWriterMaxPendingBatches is a max number of full batches waiting to be sent,
+	// batches are dropped when sending is slower than metrics are written
+	lineWriterMaxPendingBatches = 64
+)
+
+// errLineWriterQueueFull is an error logged when batch is dropped as too many batches are waiting to be sent
+var errLineWriterQueueFull = errors.New("too many metric batches are waiting to be sent")
+
+// lineBatch is a batch of lines waiting to be sent, sent channel, if set, is closed once batch is sent
+type lineBatch struct {
+	buf  []byte
+	sent chan struct{}
+}
+
+// lineWriter buffers newline-terminated metric lines and sends them in batches not bigger than maxPacketSize
+// bytes (unless a single line is bigger) periodically, when buffer is full and on close.
+// Batches are sent by the sender goroutine, so that writes do not wait for slow or unavailable backend.
+type lineWriter struct {
+	sync.Mutex
+
+	send          func([]byte) error
+	maxPacketSize int
+	buf           []byte
+	closed        bool
+
+	batches  chan lineBatch
+	done     chan struct{}
+	wg       sync.WaitGroup
+	flushing sync.WaitGroup
+}
+
+func newLineWriter(send func([]byte) error, maxPacketSize int, flushPeriod time.Duration) *lineWriter {
+	w := &lineWriter{
+		send:          send,
+		maxPacketSize: maxPacketSize,
+		buf:           make([]byte, 0, maxPacketSize),
+		batches:       make(chan lineBatch, lineWriterMaxPendingBatches),
+		done:          make(chan struct{}),
+	}
+
+	w.wg.Add(1)
+	go w.sendBatches(flushPeriod)
+
+	return w
+}
+
+// sendBatches sends queued batches and buffered lines every flush period, if set, until writer is closed
+func (w *lineWriter) sendBatches(flushPeriod time.Duration) {
+	defer w.wg.Done()
+
+	var tick <-chan time.Time
+	if flushPeriod > 0 {
+		ticker := time.NewTicker(flushPeriod)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case batch := <-w.batches:
+			w.sendBatch(batch)
+		case <-tick:
+			w.Lock()
+			buf := w.swap()
+			w.Unlock()
+			w.sendBuf(buf)
+		case <-w.done:
+			for {
+				select {
+				case batch := <-w.batches:
+					w.sendBatch(batch)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (w *lineWriter) sendBatch(batch lineBatch) {
+	w.sendBuf(batch.buf)
+	if batch.sent != nil {
+		close(batch.sent)
+	}
+}
+
+func (w *lineWriter) sendBuf(buf []byte) {
+	if len(buf) == 0 {
+		return
+	}
+
+	if err := w.send(buf); err != nil {
+		log.Log("An error occurred while sending metrics", map[string]interface{}{
+			"bytes": len(buf),
+		}, err)
+	}
+}
+
+// swap returns buffered lines replacing buffer with the new one, must be called under lock
+func (w *lineWriter) swap() []byte {
+	if len(w.buf) == 0 {
+		return nil
+	}
+
+	buf := w.buf
+	w.buf = make([]byte, 0, w.maxPacketSize)
+
+	return buf
+}
+
+// Write appends line to the buffer, line must be terminated with new line character
+func (w *lineWriter) Write(line []byte) {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.closed {
+		return
+	}
+
+	if len(w.buf) > 0 && len(w.buf)+len(line) > w.maxPacketSize {
+		buf := w.swap()
+		select {
+		case w.batches <- lineBatch{buf: buf}:
+		default:
+			log.Log("An error occurred while sending metrics", map[string]interface{}{
+				"bytes": len(buf),
+			}, errLineWriterQueueFull)
+		}
+	}
+	w.buf = append(w.buf, line...)
+}
+
+// Flush sends all buffered lines and waits until lines written before are sent
+func (w *lineWriter) Flush() {
+	w.Lock()
+	if w.closed {
+		w.Unlock()
+		return
+	}
+	batch := lineBatch{buf: w.swap(), sent: make(chan struct{})}
+	w.flushing.Add(1)
+	w.Unlock()
+
+	defer w.flushing.Done()
+
+	w.batches <- batch
+	<-batch.sent
+}
+
+// Close sends buffered lines and stops sender goroutine
+func (w *lineWriter) Close() {
+	w.Lock()
+	if w.closed {
+		w.Unlock()
+		return
+	}
+	w.closed = true
+	buf := w.swap()
+	w.Unlock()
+
+	w.flushing.Wait()
+	close(w.done)
+	w.wg.Wait()
+
+	w.sendBuf(buf)
+}
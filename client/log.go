@@ -16,6 +16,7 @@ type Log struct {
 	sync.Mutex
 	httpMetricCallback bucket.HTTPMetricNameAlterCallback
 	httpRequestSection string
+	operationDepth     int
 	unicode            bool
 }
 
@@ -39,7 +40,7 @@ func (c *Log) Close() error {
 
 // TrackRequest tracks HTTP Request stats
 func (c *Log) TrackRequest(r *http.Request, t timer.Timer, success bool) Client {
-	b := bucket.NewHTTPRequest(c.httpRequestSection, r, success, c.httpMetricCallback, c.unicode)
+	b := bucket.NewHTTPRequestWithDepth(c.httpRequestSection, r, success, c.httpMetricCallback, c.unicode, c.operationDepth)
 	i := &incrementer.Log{}
 
 	if nil != t {
@@ -56,7 +57,7 @@ func (c *Log) TrackRequest(r *http.Request, t timer.Timer, success bool) Client
 
 // TrackOperation tracks custom operation
 func (c *Log) TrackOperation(section string, operation *bucket.MetricOperation, t timer.Timer, success bool) Client {
-	b := bucket.NewPlain(section, operation, success, c.unicode)
+	b := bucket.NewPlainWithDepth(section, operation, success, c.unicode, c.operationDepth)
 	i := &incrementer.Log{}
 
 	if nil != t {
@@ -72,7 +73,7 @@ func (c *Log) TrackOperation(section string, operation *bucket.MetricOperation,
 
 // TrackOperationN tracks custom operation with n diff
 func (c *Log) TrackOperationN(section string, operation *bucket.MetricOperation, t timer.Timer, n int, success bool) Client {
-	b := bucket.NewPlain(section, operation, success, c.unicode)
+	b := bucket.NewPlainWithDepth(section, operation, success, c.unicode, c.operationDepth)
 	i := &incrementer.Log{}
 
 	if nil != t {
@@ -88,7 +89,7 @@ func (c *Log) TrackOperationN(section string, operation *bucket.MetricOperation,
 
 // TrackMetric tracks custom metric, w/out ok/fail additional sections
 func (c *Log) TrackMetric(section string, operation *bucket.MetricOperation) Client {
-	b := bucket.NewPlain(section, operation, true, c.unicode)
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
 	i := &incrementer.Log{}
 
 	i.Increment(b.Metric())
@@ -99,7 +100,7 @@ func (c *Log) TrackMetric(section string, operation *bucket.MetricOperation) Cli
 
 // TrackMetricN tracks custom metric with n diff, w/out ok/fail additional sections
 func (c *Log) TrackMetricN(section string, operation *bucket.MetricOperation, n int) Client {
-	b := bucket.NewPlain(section, operation, true, c.unicode)
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
 	i := &incrementer.Log{}
 
 	i.IncrementN(b.Metric(), n)
@@ -110,7 +111,7 @@ func (c *Log) TrackMetricN(section string, operation *bucket.MetricOperation, n
 
 // TrackState tracks metric absolute value
 func (c *Log) TrackState(section string, operation *bucket.MetricOperation, value int) Client {
-	b := bucket.NewPlain(section, operation, true, c.unicode)
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
 	s := &state.Log{}
 
 	s.Set(b.Metric(), value)
@@ -118,6 +119,60 @@ func (c *Log) TrackState(section string, operation *bucket.MetricOperation, valu
 	return c
 }
 
+// TrackStateFloat tracks metric absolute float value
+func (c *Log) TrackStateFloat(section string, operation *bucket.MetricOperation, value float64) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
+	s := &state.Log{}
+
+	s.SetFloat(b.Metric(), value)
+
+	return c
+}
+
+// TrackStateAdd increases metric state by delta
+func (c *Log) TrackStateAdd(section string, operation *bucket.MetricOperation, delta float64) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
+	s := &state.Log{}
+
+	s.Add(b.Metric(), delta)
+
+	return c
+}
+
+// TrackStateSub decreases metric state by delta
+func (c *Log) TrackStateSub(section string, operation *bucket.MetricOperation, delta float64) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
+	s := &state.Log{}
+
+	s.Sub(b.Metric(), delta)
+
+	return c
+}
+
+// TrackSummary tracks metric value distribution
+func (c *Log) TrackSummary(section string, operation *bucket.MetricOperation, value float64) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
+
+	log.Log("Stats summary observed", map[string]interface{}{
+		"bucket": b.Metric(),
+		"value":  value,
+	}, nil)
+
+	return c
+}
+
+// TrackUnique tracks number of unique values
+func (c *Log) TrackUnique(section string, operation *bucket.MetricOperation, value string) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
+
+	log.Log("Stats unique value tracked", map[string]interface{}{
+		"bucket": b.Metric(),
+		"value":  value,
+	}, nil)
+
+	return c
+}
+
 // SetHTTPMetricCallback sets callback handler that allows metric operation alteration for HTTP Request
 func (c *Log) SetHTTPMetricCallback(callback bucket.HTTPMetricNameAlterCallback) Client {
 	c.Lock()
@@ -149,6 +204,26 @@ func (c *Log) ResetHTTPRequestSection() Client {
 	return c.SetHTTPRequestSection(bucket.SectionRequest)
 }
 
+// SetOperationDepth sets number of operations in metric names, shorter operations are padded with placeholders
+// and longer ones are truncated, zero value means bucket.MetricOperationsMaxLength
+func (c *Log) SetOperationDepth(depth int) Client {
+	c.Lock()
+	defer c.Unlock()
+
+	c.operationDepth = depth
+	return c
+}
+
+// With returns child client that adds given labels to every tracked metric operation
+func (c *Log) With(labels map[string]string) Client {
+	return newScoped(c, labels, "")
+}
+
+// WithSection returns child client that prefixes every tracked metric section with the given prefix
+func (c *Log) WithSection(prefix string) Client {
+	return newScoped(c, nil, prefix)
+}
+
 // Handler returns metrics endpoint for prometheus backend
 func (c *Log) Handler() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -2,6 +2,7 @@ package client
 
 import (
 	"net/http"
+	"sort"
 	"sync"
 	"time"
 
@@ -22,11 +23,18 @@ type Memory struct {
 	sync.Mutex
 	httpMetricCallback bucket.HTTPMetricNameAlterCallback
 	httpRequestSection string
+	operationDepth     int
 	unicode            bool
 
 	TimerMetrics []Metric
 	CountMetrics map[string]int
 	StateMetrics map[string]int
+	// FloatStateMetrics holds the same states as StateMetrics, but w/out truncating float values
+	FloatStateMetrics map[string]float64
+	// SummaryMetrics holds all values observed with TrackSummary by bucket
+	SummaryMetrics map[string][]float64
+	// UniqueMetrics holds all unique values tracked with TrackUnique by bucket
+	UniqueMetrics map[string]map[string]struct{}
 }
 
 // NewMemory builds and returns new Memory instance
@@ -42,6 +50,9 @@ func (c *Memory) resetMetrics() {
 	c.TimerMetrics = []Metric{}
 	c.CountMetrics = map[string]int{}
 	c.StateMetrics = map[string]int{}
+	c.FloatStateMetrics = map[string]float64{}
+	c.SummaryMetrics = map[string][]float64{}
+	c.UniqueMetrics = map[string]map[string]struct{}{}
 }
 
 // BuildTimer builds timer to track metric timings
@@ -57,7 +68,7 @@ func (c *Memory) Close() error {
 
 // TrackRequest tracks HTTP Request stats
 func (c *Memory) TrackRequest(r *http.Request, t timer.Timer, success bool) Client {
-	b := bucket.NewHTTPRequest(c.httpRequestSection, r, success, c.httpMetricCallback, c.unicode)
+	b := bucket.NewHTTPRequestWithDepth(c.httpRequestSection, r, success, c.httpMetricCallback, c.unicode, c.operationDepth)
 	i := incrementer.NewMemory()
 
 	if nil != t {
@@ -74,7 +85,7 @@ func (c *Memory) TrackRequest(r *http.Request, t timer.Timer, success bool) Clie
 
 // TrackOperation tracks custom operation
 func (c *Memory) TrackOperation(section string, operation *bucket.MetricOperation, t timer.Timer, success bool) Client {
-	b := bucket.NewPlain(section, operation, success, true)
+	b := bucket.NewPlainWithDepth(section, operation, success, true, c.operationDepth)
 	i := incrementer.NewMemory()
 
 	if nil != t {
@@ -91,7 +102,7 @@ func (c *Memory) TrackOperation(section string, operation *bucket.MetricOperatio
 
 // TrackOperationN tracks custom operation with n diff
 func (c *Memory) TrackOperationN(section string, operation *bucket.MetricOperation, t timer.Timer, n int, success bool) Client {
-	b := bucket.NewPlain(section, operation, success, true)
+	b := bucket.NewPlainWithDepth(section, operation, success, true, c.operationDepth)
 	i := incrementer.NewMemory()
 
 	if nil != t {
@@ -108,7 +119,7 @@ func (c *Memory) TrackOperationN(section string, operation *bucket.MetricOperati
 
 // TrackMetric tracks custom metric, w/out ok/fail additional sections
 func (c *Memory) TrackMetric(section string, operation *bucket.MetricOperation) Client {
-	b := bucket.NewPlain(section, operation, true, true)
+	b := bucket.NewPlainWithDepth(section, operation, true, true, c.operationDepth)
 	i := incrementer.NewMemory()
 
 	i.Increment(b.Metric())
@@ -122,7 +133,7 @@ func (c *Memory) TrackMetric(section string, operation *bucket.MetricOperation)
 
 // TrackMetricN tracks custom metric with n diff, w/out ok/fail additional sections
 func (c *Memory) TrackMetricN(section string, operation *bucket.MetricOperation, n int) Client {
-	b := bucket.NewPlain(section, operation, true, true)
+	b := bucket.NewPlainWithDepth(section, operation, true, true, c.operationDepth)
 	i := incrementer.NewMemory()
 
 	i.IncrementN(b.Metric(), n)
@@ -136,13 +147,89 @@ func (c *Memory) TrackMetricN(section string, operation *bucket.MetricOperation,
 
 // TrackState tracks metric absolute value
 func (c *Memory) TrackState(section string, operation *bucket.MetricOperation, value int) Client {
-	b := bucket.NewPlain(section, operation, true, true)
+	b := bucket.NewPlainWithDepth(section, operation, true, true, c.operationDepth)
 	s := state.NewMemory()
 
 	s.Set(b.Metric(), value)
-	for metric, value := range s.Metrics() {
-		c.StateMetrics[metric] = value
+	c.storeStates(s)
+
+	return c
+}
+
+// TrackStateFloat tracks metric absolute float value
+func (c *Memory) TrackStateFloat(section string, operation *bucket.MetricOperation, value float64) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, true, c.operationDepth)
+	s := state.NewMemory()
+
+	s.SetFloat(b.Metric(), value)
+	c.storeStates(s)
+
+	return c
+}
+
+// TrackStateAdd increases metric state by delta
+func (c *Memory) TrackStateAdd(section string, operation *bucket.MetricOperation, delta float64) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, true, c.operationDepth)
+	s := state.NewMemory()
+
+	s.SetFloat(b.Metric(), c.FloatStateMetrics[b.Metric()])
+	s.Add(b.Metric(), delta)
+	c.storeStates(s)
+
+	return c
+}
+
+// TrackStateSub decreases metric state by delta
+func (c *Memory) TrackStateSub(section string, operation *bucket.MetricOperation, delta float64) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, true, c.operationDepth)
+	s := state.NewMemory()
+
+	s.SetFloat(b.Metric(), c.FloatStateMetrics[b.Metric()])
+	s.Sub(b.Metric(), delta)
+	c.storeStates(s)
+
+	return c
+}
+
+func (c *Memory) storeStates(s *state.Memory) {
+	for metric, value := range s.FloatMetrics() {
+		c.StateMetrics[metric] = int(value)
+		c.FloatStateMetrics[metric] = value
 	}
+}
+
+// TrackSummary tracks metric value distribution
+func (c *Memory) TrackSummary(section string, operation *bucket.MetricOperation, value float64) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, true, c.operationDepth)
+
+	c.SummaryMetrics[b.Metric()] = append(c.SummaryMetrics[b.Metric()], value)
+
+	return c
+}
+
+// Quantile returns q-quantile, 0 <= q <= 1, of values observed for the bucket with TrackSummary
+// using nearest-rank method, 0 is returned if there are no observations
+func (c *Memory) Quantile(bucket string, q float64) float64 {
+	values := make([]float64, len(c.SummaryMetrics[bucket]))
+	copy(values, c.SummaryMetrics[bucket])
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Float64s(values)
+
+	return nearestRank(values, q)
+}
+
+// TrackUnique tracks number of unique values
+func (c *Memory) TrackUnique(section string, operation *bucket.MetricOperation, value string) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, true, c.operationDepth)
+
+	values, ok := c.UniqueMetrics[b.Metric()]
+	if !ok {
+		values = make(map[string]struct{})
+		c.UniqueMetrics[b.Metric()] = values
+	}
+	values[value] = struct{}{}
 
 	return c
 }
@@ -178,6 +265,26 @@ func (c *Memory) ResetHTTPRequestSection() Client {
 	return c.SetHTTPRequestSection(bucket.SectionRequest)
 }
 
+// SetOperationDepth sets number of operations in metric names, shorter operations are padded with placeholders
+// and longer ones are truncated, zero value means bucket.MetricOperationsMaxLength
+func (c *Memory) SetOperationDepth(depth int) Client {
+	c.Lock()
+	defer c.Unlock()
+
+	c.operationDepth = depth
+	return c
+}
+
+// With returns child client that adds given labels to every tracked metric operation
+func (c *Memory) With(labels map[string]string) Client {
+	return newScoped(c, labels, "")
+}
+
+// WithSection returns child client that prefixes every tracked metric section with the given prefix
+func (c *Memory) WithSection(prefix string) Client {
+	return newScoped(c, nil, prefix)
+}
+
 // Handler returns metrics endpoint for prometheus backend
 func (c *Memory) Handler() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -192,3 +192,79 @@ func TestMemoryClient_SetHTTPRequestSection(t *testing.T) {
 	client.ResetHTTPRequestSection()
 	assert.Equal(t, bucket.SectionRequest, client.httpRequestSection)
 }
+
+func TestMemoryClient_SetOperationDepth(t *testing.T) {
+	client := NewMemory(false)
+	client.SetOperationDepth(5)
+
+	client.TrackMetric("section", bucket.NewMetricOperation("foo", "bar", "baz", "qux"))
+	assert.Equal(t, 1, client.CountMetrics["section.foo.bar.baz.qux.-"])
+
+	client.TrackRequest(&http.Request{Method: http.MethodGet, URL: &url.URL{Path: "/users/1/orders/2/items"}}, nil, true)
+	assert.Equal(t, 1, client.CountMetrics["request.get.users.1.orders.2"])
+
+	client.SetOperationDepth(0)
+	client.TrackMetric("section", bucket.NewMetricOperation("foo", "bar", "baz", "qux"))
+	assert.Equal(t, 1, client.CountMetrics["section.foo.bar.baz"])
+}
+
+func TestMemoryClient_TrackSummary(t *testing.T) {
+	client := NewMemory(true)
+
+	section := "test-section"
+	operation := bucket.NewMetricOperation("queue", "lag")
+	b := bucket.NewPlain(section, operation, true, true)
+
+	assert.Equal(t, float64(0), client.Quantile(b.Metric(), 0.5))
+
+	for _, value := range []float64{5, 1, 4, 2, 3} {
+		client.TrackSummary(section, operation, value)
+	}
+
+	assert.Equal(t, []float64{5, 1, 4, 2, 3}, client.SummaryMetrics[b.Metric()])
+	assert.Equal(t, float64(1), client.Quantile(b.Metric(), 0))
+	assert.Equal(t, float64(3), client.Quantile(b.Metric(), 0.5))
+	assert.Equal(t, float64(5), client.Quantile(b.Metric(), 0.99))
+	assert.Equal(t, float64(5), client.Quantile(b.Metric(), 1))
+
+	client.Close()
+	assert.Equal(t, 0, len(client.SummaryMetrics))
+}
+
+func TestMemoryClient_TrackStateFloat(t *testing.T) {
+	client := NewMemory(true)
+
+	section := "test-section"
+	operation := bucket.NewMetricOperation("memory", "used")
+	b := bucket.NewPlain(section, operation, true, true)
+
+	client.TrackStateFloat(section, operation, 1.5)
+	assert.Equal(t, 1.5, client.FloatStateMetrics[b.Metric()])
+	assert.Equal(t, 1, client.StateMetrics[b.Metric()])
+
+	client.TrackStateAdd(section, operation, 2)
+	client.TrackStateSub(section, operation, 0.25)
+	assert.Equal(t, 3.25, client.FloatStateMetrics[b.Metric()])
+	assert.Equal(t, 3, client.StateMetrics[b.Metric()])
+
+	client.TrackState(section, operation, 10)
+	assert.Equal(t, float64(10), client.FloatStateMetrics[b.Metric()])
+	assert.Equal(t, 10, client.StateMetrics[b.Metric()])
+}
+
+func TestMemoryClient_TrackUnique(t *testing.T) {
+	client := NewMemory(true)
+
+	section := "test-section"
+	operation := bucket.NewMetricOperation("users")
+	b := bucket.NewPlain(section, operation, true, true)
+
+	for _, user := range []string{"u1", "u2", "u1", "u3"} {
+		client.TrackUnique(section, operation, user)
+	}
+
+	assert.Equal(t, map[string]struct{}{"u1": {}, "u2": {}, "u3": {}}, client.UniqueMetrics[b.Metric()])
+
+	client.Close()
+	assert.Equal(t, 0, len(client.UniqueMetrics))
+}
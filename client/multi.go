@@ -0,0 +1,276 @@
+package client
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	"github.com/hellofresh/stats-go/bucket"
+	"github.com/hellofresh/stats-go/timer"
+)
+
+// MultiError is an error returned by Multi client when one or more underlying clients failed to close
+type MultiError []error
+
+// Error returns all underlying errors messages joined together
+func (e MultiError) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Multi is Client implementation that forwards all the calls to the list of clients,
+// e.g. to migrate from one metrics backend to another w/out losing metrics in between.
+// Every client gets own copy of metric operation, so clients that alter operation labels do not affect each other,
+// and timer finished once, so all the clients track the same duration.
+type Multi struct {
+	clients []Client
+}
+
+// NewMulti builds and returns new Multi instance for the given clients
+func NewMulti(clients ...Client) *Multi {
+	return &Multi{clients: clients}
+}
+
+// Clients returns a list of clients all the calls are forwarded to
+func (c *Multi) Clients() []Client {
+	return c.clients
+}
+
+// BuildTimer builds timer to track metric timings
+func (c *Multi) BuildTimer() timer.Timer {
+	return &timer.Memory{}
+}
+
+// Close closes all underlying clients, returns MultiError with all failed clients errors if any
+func (c *Multi) Close() error {
+	var errs MultiError
+	for _, client := range c.clients {
+		if err := client.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// TrackRequest tracks HTTP Request stats
+func (c *Multi) TrackRequest(r *http.Request, t timer.Timer, success bool) Client {
+	t = finishedTimer(t)
+	for _, client := range c.clients {
+		client.TrackRequest(r, t, success)
+	}
+
+	return c
+}
+
+// TrackOperation tracks custom operation
+func (c *Multi) TrackOperation(section string, operation *bucket.MetricOperation, t timer.Timer, success bool) Client {
+	t = finishedTimer(t)
+	for _, client := range c.clients {
+		client.TrackOperation(section, operation.Clone(), t, success)
+	}
+
+	return c
+}
+
+// TrackOperationN tracks custom operation with n diff
+func (c *Multi) TrackOperationN(section string, operation *bucket.MetricOperation, t timer.Timer, n int, success bool) Client {
+	t = finishedTimer(t)
+	for _, client := range c.clients {
+		client.TrackOperationN(section, operation.Clone(), t, n, success)
+	}
+
+	return c
+}
+
+// TrackMetric tracks custom metric, w/out ok/fail additional sections
+func (c *Multi) TrackMetric(section string, operation *bucket.MetricOperation) Client {
+	for _, client := range c.clients {
+		client.TrackMetric(section, operation.Clone())
+	}
+
+	return c
+}
+
+// TrackMetricN tracks custom metric with n diff, w/out ok/fail additional sections
+func (c *Multi) TrackMetricN(section string, operation *bucket.MetricOperation, n int) Client {
+	for _, client := range c.clients {
+		client.TrackMetricN(section, operation.Clone(), n)
+	}
+
+	return c
+}
+
+// TrackState tracks metric absolute value
+func (c *Multi) TrackState(section string, operation *bucket.MetricOperation, value int) Client {
+	for _, client := range c.clients {
+		client.TrackState(section, operation.Clone(), value)
+	}
+
+	return c
+}
+
+// TrackStateFloat tracks metric absolute float value
+func (c *Multi) TrackStateFloat(section string, operation *bucket.MetricOperation, value float64) Client {
+	for _, client := range c.clients {
+		client.TrackStateFloat(section, operation.Clone(), value)
+	}
+
+	return c
+}
+
+// TrackStateAdd increases metric state by delta
+func (c *Multi) TrackStateAdd(section string, operation *bucket.MetricOperation, delta float64) Client {
+	for _, client := range c.clients {
+		client.TrackStateAdd(section, operation.Clone(), delta)
+	}
+
+	return c
+}
+
+// TrackStateSub decreases metric state by delta
+func (c *Multi) TrackStateSub(section string, operation *bucket.MetricOperation, delta float64) Client {
+	for _, client := range c.clients {
+		client.TrackStateSub(section, operation.Clone(), delta)
+	}
+
+	return c
+}
+
+// TrackSummary tracks metric value distribution
+func (c *Multi) TrackSummary(section string, operation *bucket.MetricOperation, value float64) Client {
+	for _, client := range c.clients {
+		client.TrackSummary(section, operation.Clone(), value)
+	}
+
+	return c
+}
+
+// TrackUnique tracks number of unique values
+func (c *Multi) TrackUnique(section string, operation *bucket.MetricOperation, value string) Client {
+	for _, client := range c.clients {
+		client.TrackUnique(section, operation.Clone(), value)
+	}
+
+	return c
+}
+
+// SetHTTPMetricCallback sets callback handler that allows metric operation alteration for HTTP Request
+func (c *Multi) SetHTTPMetricCallback(callback bucket.HTTPMetricNameAlterCallback) Client {
+	for _, client := range c.clients {
+		client.SetHTTPMetricCallback(callback)
+	}
+
+	return c
+}
+
+// GetHTTPMetricCallback gets callback handler that allows metric operation alteration for HTTP Request
+func (c *Multi) GetHTTPMetricCallback() bucket.HTTPMetricNameAlterCallback {
+	if len(c.clients) == 0 {
+		return nil
+	}
+
+	return c.clients[0].GetHTTPMetricCallback()
+}
+
+// SetHTTPRequestSection sets metric section for HTTP Request metrics
+func (c *Multi) SetHTTPRequestSection(section string) Client {
+	for _, client := range c.clients {
+		client.SetHTTPRequestSection(section)
+	}
+
+	return c
+}
+
+// ResetHTTPRequestSection resets metric section for HTTP Request metrics to default value that is "request"
+func (c *Multi) ResetHTTPRequestSection() Client {
+	for _, client := range c.clients {
+		client.ResetHTTPRequestSection()
+	}
+
+	return c
+}
+
+// SetOperationDepth sets number of operations in metric names for all underlying clients
+func (c *Multi) SetOperationDepth(depth int) Client {
+	for _, client := range c.clients {
+		client.SetOperationDepth(depth)
+	}
+
+	return c
+}
+
+// With returns child client that adds given labels to every tracked metric operation
+func (c *Multi) With(labels map[string]string) Client {
+	return newScoped(c, labels, "")
+}
+
+// WithSection returns child client that prefixes every tracked metric section with the given prefix
+func (c *Multi) WithSection(prefix string) Client {
+	return newScoped(c, nil, prefix)
+}
+
+// Handler returns metrics endpoint of the first client that serves one, e.g. prometheus backend,
+// clients that do not serve metrics endpoint respond with "405 Method Not Allowed" and are skipped
+func (c *Multi) Handler() http.Handler {
+	handlers := make([]http.Handler, 0, len(c.clients))
+	for _, client := range c.clients {
+		handlers = append(handlers, client.Handler())
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, handler := range handlers {
+			rw := newBufferedResponseWriter()
+			handler.ServeHTTP(rw, r)
+
+			if rw.status != http.StatusMethodNotAllowed {
+				rw.writeTo(w)
+				return
+			}
+		}
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+}
+
+// bufferedResponseWriter is http.ResponseWriter implementation that keeps response in memory
+type bufferedResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newBufferedResponseWriter() *bufferedResponseWriter {
+	return &bufferedResponseWriter{header: make(http.Header), status: http.StatusOK}
+}
+
+// Header returns response headers
+func (w *bufferedResponseWriter) Header() http.Header {
+	return w.header
+}
+
+// Write writes response body
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+// WriteHeader sets response status code
+func (w *bufferedResponseWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+// writeTo writes buffered response to the given writer
+func (w *bufferedResponseWriter) writeTo(rw http.ResponseWriter) {
+	for k, v := range w.header {
+		rw.Header()[k] = v
+	}
+	rw.WriteHeader(w.status)
+	rw.Write(w.body.Bytes())
+}
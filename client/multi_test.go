@@ -0,0 +1,109 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/hellofresh/stats-go/bucket"
+	"github.com/hellofresh/stats-go/timer"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingCloseClient struct {
+	*Noop
+	err error
+}
+
+func (c *failingCloseClient) Close() error {
+	return c.err
+}
+
+func TestMulti_Track(t *testing.T) {
+	memory1 := NewMemory(false)
+	memory2 := NewMemory(false)
+	client := NewMulti(memory1, memory2)
+
+	operation := bucket.NewMetricOperation("foo").WithLabels(map[string]string{"country": "de"})
+	client.TrackOperation("section", operation, client.BuildTimer().Start(), true)
+	client.TrackMetricN("section", operation, 3)
+	client.TrackState("section", operation, 5)
+	client.TrackSummary("section", operation, 1.5)
+	client.TrackUnique("section", operation, "user")
+
+	for _, memory := range []*Memory{memory1, memory2} {
+		assert.Equal(t, 1, len(memory.TimerMetrics))
+		assert.Equal(t, 4, memory.CountMetrics["section.foo.-.-"])
+		assert.Equal(t, 5, memory.StateMetrics["section.foo.-.-"])
+		assert.Equal(t, []float64{1.5}, memory.SummaryMetrics["section.foo.-.-"])
+		assert.Len(t, memory.UniqueMetrics["section.foo.-.-"], 1)
+	}
+	assert.Equal(t, map[string]string{"country": "de"}, operation.Labels)
+
+	client.SetHTTPRequestSection("api")
+	client.TrackRequest(&http.Request{Method: http.MethodGet, URL: &url.URL{Path: "/foo"}}, nil, true)
+	assert.Equal(t, 1, memory1.CountMetrics["api.get.foo.-"])
+	assert.Equal(t, 1, memory2.CountMetrics["api.get.foo.-"])
+}
+
+// slowClient is Noop client that takes time to track operations
+type slowClient struct {
+	*Noop
+}
+
+func (c *slowClient) TrackOperation(section string, operation *bucket.MetricOperation, t timer.Timer, success bool) Client {
+	time.Sleep(10 * time.Millisecond)
+	return c
+}
+
+func TestMulti_TrackSameDuration(t *testing.T) {
+	memory1 := NewMemory(false)
+	memory2 := NewMemory(false)
+	client := NewMulti(memory1, &slowClient{NewNoop()}, memory2)
+
+	operation := bucket.NewMetricOperation("foo")
+	client.TrackOperation("section", operation, (&timer.Memory{}).Start(), true)
+	client.TrackOperationN("section", operation, (&timer.Memory{}).Start(), 2, true)
+
+	require.Equal(t, 2, len(memory1.TimerMetrics))
+	require.Equal(t, 2, len(memory2.TimerMetrics))
+	for i := range memory1.TimerMetrics {
+		assert.Equal(t, memory1.TimerMetrics[i].Elapsed, memory2.TimerMetrics[i].Elapsed)
+	}
+}
+
+func TestMulti_Close(t *testing.T) {
+	err1 := errors.New("error 1")
+	err2 := errors.New("error 2")
+
+	client := NewMulti(&failingCloseClient{NewNoop(), err1}, NewNoop(), &failingCloseClient{NewNoop(), err2})
+	err := client.Close()
+
+	require.IsType(t, MultiError{}, err)
+	assert.Equal(t, MultiError{err1, err2}, err)
+	assert.Equal(t, "error 1; error 2", err.Error())
+
+	assert.NoError(t, NewMulti(NewNoop(), NewMemory(false)).Close())
+}
+
+func TestMulti_Handler(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	promClient := newRegistryPrometheus("namespace", registry)
+
+	client := NewMulti(NewNoop(), promClient)
+	client.TrackMetric("section", bucket.NewMetricOperation("foo"))
+
+	w := httptest.NewRecorder()
+	client.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "namespace_section_foo")
+
+	w = httptest.NewRecorder()
+	NewMulti(NewNoop(), NewMemory(false)).Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
@@ -60,6 +60,31 @@ func (c *Noop) TrackState(section string, operation *bucket.MetricOperation, val
 	return c
 }
 
+// TrackStateFloat tracks metric absolute float value
+func (c *Noop) TrackStateFloat(section string, operation *bucket.MetricOperation, value float64) Client {
+	return c
+}
+
+// TrackStateAdd increases metric state by delta
+func (c *Noop) TrackStateAdd(section string, operation *bucket.MetricOperation, delta float64) Client {
+	return c
+}
+
+// TrackStateSub decreases metric state by delta
+func (c *Noop) TrackStateSub(section string, operation *bucket.MetricOperation, delta float64) Client {
+	return c
+}
+
+// TrackSummary tracks metric value distribution
+func (c *Noop) TrackSummary(section string, operation *bucket.MetricOperation, value float64) Client {
+	return c
+}
+
+// TrackUnique tracks number of unique values
+func (c *Noop) TrackUnique(section string, operation *bucket.MetricOperation, value string) Client {
+	return c
+}
+
 // SetHTTPMetricCallback sets callback handler that allows metric operation alteration for HTTP Request
 func (c *Noop) SetHTTPMetricCallback(callback bucket.HTTPMetricNameAlterCallback) Client {
 	c.Lock()
@@ -87,6 +112,21 @@ func (c *Noop) ResetHTTPRequestSection() Client {
 	return c
 }
 
+// SetOperationDepth sets number of operations in metric names
+func (c *Noop) SetOperationDepth(depth int) Client {
+	return c
+}
+
+// With returns child client that adds given labels to every tracked metric operation
+func (c *Noop) With(labels map[string]string) Client {
+	return newScoped(c, labels, "")
+}
+
+// WithSection returns child client that prefixes every tracked metric section with the given prefix
+func (c *Noop) WithSection(prefix string) Client {
+	return newScoped(c, nil, prefix)
+}
+
 // Handler returns metrics endpoint for prometheus backend
 func (c *Noop) Handler() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
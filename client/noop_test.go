@@ -21,6 +21,11 @@ func TestNoopClient(t *testing.T) {
 	assert.Equal(t, client, client.TrackMetric("", &bucket.MetricOperation{}))
 	assert.Equal(t, client, client.TrackMetricN("", &bucket.MetricOperation{}, 0))
 	assert.Equal(t, client, client.TrackState("", &bucket.MetricOperation{}, 0))
+	assert.Equal(t, client, client.TrackStateFloat("", &bucket.MetricOperation{}, 0))
+	assert.Equal(t, client, client.TrackStateAdd("", &bucket.MetricOperation{}, 0))
+	assert.Equal(t, client, client.TrackStateSub("", &bucket.MetricOperation{}, 0))
+	assert.Equal(t, client, client.TrackSummary("", &bucket.MetricOperation{}, 0))
+	assert.Equal(t, client, client.TrackUnique("", &bucket.MetricOperation{}, ""))
 	assert.Equal(t, client, client.SetHTTPRequestSection(""))
 	assert.Equal(t, client, client.ResetHTTPRequestSection())
 	assert.Equal(t, client, client.SetHTTPMetricCallback(func(metricParts *bucket.MetricOperation, r *http.Request) *bucket.MetricOperation {
@@ -0,0 +1,573 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hellofresh/stats-go/bucket"
+	"github.com/hellofresh/stats-go/internal/labelset"
+	"github.com/hellofresh/stats-go/log"
+	"github.com/hellofresh/stats-go/timer"
+)
+
+const (
+	// DefaultOTLPExportInterval is a default interval OTLP client exports metrics with
+	DefaultOTLPExportInterval = 10 * time.Second
+
+	otlpScopeName      = "github.com/hellofresh/stats-go"
+	otlpServiceNameKey = "service.name"
+	otlpMetricsPath    = "/v1/metrics"
+	otlpExportTimeout  = 10 * time.Second
+	otlpUnitSeconds    = "s"
+	// otlpHistogramSuffix is a timing histogram name suffix, so that it does not clash with operation counter sum
+	otlpHistogramSuffix = "_seconds"
+)
+
+// DefaultOTLPHistogramBoundaries are default histogram bucket boundaries in seconds for OTLP client timings
+var DefaultOTLPHistogramBoundaries = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// otlpDataPoint is an aggregated value of a single metric attributes set
+type otlpDataPoint struct {
+	labels map[string]string
+	value  int64
+	// double is a gauge value if it was set as float value or changed with relative update
+	double   float64
+	isDouble bool
+	// uniques is a unique values set of TrackUnique gauge
+	uniques *hyperLogLog
+
+	count   uint64
+	sum     float64
+	buckets []uint64
+	min     float64
+	max     float64
+}
+
+// OTLP is Client implementation for OpenTelemetry collector that exports metrics over OTLP/HTTP in JSON encoding.
+// Metric names and labels are the same as for Prometheus client, operation timings are exported as
+// cumulative histograms in seconds with "_seconds" name suffix, TrackMetric and TrackMetricN as cumulative
+// monotonic sums, TrackState as gauges (float gauges and gauges changed with relative updates are exported
+// as double values), TrackUnique as gauges with estimated number of unique values observed since client start
+// and TrackSummary as summaries with count, sum, minimum (0 quantile) and maximum (1 quantile) values.
+type OTLP struct {
+	sync.Mutex
+	httpMetricCallback bucket.HTTPMetricNameAlterCallback
+	httpRequestSection string
+	operationDepth     int
+	unicode            bool
+
+	namespace   string
+	endpoint    string
+	serviceName string
+	httpClient  *http.Client
+	startTime   time.Time
+	done        chan struct{}
+	wg          sync.WaitGroup
+	closed      bool
+
+	metricsMu  sync.Mutex
+	sums       map[string]map[string]*otlpDataPoint
+	gauges     map[string]map[string]*otlpDataPoint
+	histograms map[string]map[string]*otlpDataPoint
+	summaries  map[string]map[string]*otlpDataPoint
+}
+
+// NewOTLP builds and returns new OTLP instance, endpoint is a base address of OTLP/HTTP receiver,
+// e.g. "http://localhost:4318", metrics are exported to "<endpoint>/v1/metrics"
+func NewOTLP(endpoint string, namespace string, serviceName string, exportInterval time.Duration, unicode bool) *OTLP {
+	if exportInterval <= 0 {
+		exportInterval = DefaultOTLPExportInterval
+	}
+
+	client := &OTLP{
+		unicode:     unicode,
+		namespace:   namespace,
+		endpoint:    strings.TrimRight(endpoint, "/") + otlpMetricsPath,
+		serviceName: serviceName,
+		httpClient:  &http.Client{Timeout: otlpExportTimeout},
+		startTime:   time.Now(),
+		done:        make(chan struct{}),
+		sums:        make(map[string]map[string]*otlpDataPoint),
+		gauges:      make(map[string]map[string]*otlpDataPoint),
+		histograms:  make(map[string]map[string]*otlpDataPoint),
+		summaries:   make(map[string]map[string]*otlpDataPoint),
+	}
+	client.ResetHTTPRequestSection()
+
+	client.wg.Add(1)
+	go client.exportPeriodically(exportInterval)
+
+	return client
+}
+
+func (c *OTLP) exportPeriodically(exportInterval time.Duration) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.Export(); err != nil {
+				log.Log("An error occurred while exporting metrics to OTLP receiver", map[string]interface{}{
+					"endpoint": c.endpoint,
+				}, err)
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// prepareMetric adds namespace to metric
+func (c *OTLP) prepareMetric(metric string) string {
+	if c.namespace == "" {
+		return metric
+	}
+	return c.namespace + "_" + metric
+}
+
+// dataPoint returns existing or creates new data point for metric and labels set
+func dataPoint(metrics map[string]map[string]*otlpDataPoint, name string, labels map[string]string) *otlpDataPoint {
+	points, ok := metrics[name]
+	if !ok {
+		points = make(map[string]*otlpDataPoint)
+		metrics[name] = points
+	}
+
+	keys := labelset.Keys(labels)
+	keyParts := make([]string, len(keys))
+	for i, k := range keys {
+		keyParts[i] = k + "=" + labels[k]
+	}
+	key := strings.Join(keyParts, "\xff")
+
+	point, ok := points[key]
+	if !ok {
+		pointLabels := make(map[string]string, len(labels))
+		for k, v := range labels {
+			pointLabels[k] = v
+		}
+		point = &otlpDataPoint{labels: pointLabels}
+		points[key] = point
+	}
+
+	return point
+}
+
+func (c *OTLP) add(name string, n int, labels map[string]string) {
+	c.metricsMu.Lock()
+	defer c.metricsMu.Unlock()
+
+	dataPoint(c.sums, c.prepareMetric(name), labels).value += int64(n)
+}
+
+func (c *OTLP) set(name string, value int, labels map[string]string) {
+	c.metricsMu.Lock()
+	defer c.metricsMu.Unlock()
+
+	point := dataPoint(c.gauges, c.prepareMetric(name), labels)
+	point.value = int64(value)
+	point.isDouble = false
+}
+
+func (c *OTLP) setFloat(name string, value float64, labels map[string]string) {
+	c.metricsMu.Lock()
+	defer c.metricsMu.Unlock()
+
+	point := dataPoint(c.gauges, c.prepareMetric(name), labels)
+	point.double = value
+	point.isDouble = true
+}
+
+func (c *OTLP) addFloat(name string, delta float64, labels map[string]string) {
+	c.metricsMu.Lock()
+	defer c.metricsMu.Unlock()
+
+	point := dataPoint(c.gauges, c.prepareMetric(name), labels)
+	if !point.isDouble {
+		point.double = float64(point.value)
+		point.isDouble = true
+	}
+	point.double += delta
+}
+
+func (c *OTLP) observe(name string, value float64, labels map[string]string) {
+	c.metricsMu.Lock()
+	defer c.metricsMu.Unlock()
+
+	point := dataPoint(c.histograms, c.prepareMetric(name), labels)
+	if point.buckets == nil {
+		point.buckets = make([]uint64, len(DefaultOTLPHistogramBoundaries)+1)
+	}
+
+	point.count++
+	point.sum += value
+	point.buckets[sort.SearchFloat64s(DefaultOTLPHistogramBoundaries, value)]++
+}
+
+func (c *OTLP) summarize(name string, value float64, labels map[string]string) {
+	c.metricsMu.Lock()
+	defer c.metricsMu.Unlock()
+
+	point := dataPoint(c.summaries, c.prepareMetric(name), labels)
+	if point.count == 0 || value < point.min {
+		point.min = value
+	}
+	if point.count == 0 || value > point.max {
+		point.max = value
+	}
+
+	point.count++
+	point.sum += value
+}
+
+func (c *OTLP) addUnique(name string, value string, labels map[string]string) {
+	c.metricsMu.Lock()
+	defer c.metricsMu.Unlock()
+
+	point := dataPoint(c.gauges, c.prepareMetric(name), labels)
+	if point.uniques == nil {
+		point.uniques = newHyperLogLog()
+	}
+	point.uniques.Add(value)
+	point.value = int64(math.Round(point.uniques.Estimate()))
+	point.isDouble = false
+}
+
+// buildRequest builds export request with the current state of all metrics
+func (c *OTLP) buildRequest() *otlpMetricsRequest {
+	c.metricsMu.Lock()
+	defer c.metricsMu.Unlock()
+
+	startTime := strconv.FormatInt(c.startTime.UnixNano(), 10)
+	now := strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	var metrics []otlpMetric
+	for _, name := range sortedMetricNames(c.sums) {
+		sum := &otlpSum{AggregationTemporality: otlpAggregationTemporalityCumulative, IsMonotonic: true}
+		for _, point := range sortedDataPoints(c.sums[name]) {
+			sum.DataPoints = append(sum.DataPoints, otlpNumberDataPoint{
+				Attributes:        otlpAttributes(point.labels),
+				StartTimeUnixNano: startTime,
+				TimeUnixNano:      now,
+				AsInt:             strconv.FormatInt(point.value, 10),
+			})
+		}
+		metrics = append(metrics, otlpMetric{Name: name, Sum: sum})
+	}
+
+	for _, name := range sortedMetricNames(c.gauges) {
+		gauge := &otlpGauge{}
+		for _, point := range sortedDataPoints(c.gauges[name]) {
+			dp := otlpNumberDataPoint{Attributes: otlpAttributes(point.labels), TimeUnixNano: now}
+			if point.isDouble {
+				value := point.double
+				dp.AsDouble = &value
+			} else {
+				dp.AsInt = strconv.FormatInt(point.value, 10)
+			}
+			gauge.DataPoints = append(gauge.DataPoints, dp)
+		}
+		metrics = append(metrics, otlpMetric{Name: name, Gauge: gauge})
+	}
+
+	for _, name := range sortedMetricNames(c.histograms) {
+		histogram := &otlpHistogram{AggregationTemporality: otlpAggregationTemporalityCumulative}
+		for _, point := range sortedDataPoints(c.histograms[name]) {
+			bucketCounts := make([]string, len(point.buckets))
+			for i, count := range point.buckets {
+				bucketCounts[i] = strconv.FormatUint(count, 10)
+			}
+
+			histogram.DataPoints = append(histogram.DataPoints, otlpHistogramDataPoint{
+				Attributes:        otlpAttributes(point.labels),
+				StartTimeUnixNano: startTime,
+				TimeUnixNano:      now,
+				Count:             strconv.FormatUint(point.count, 10),
+				Sum:               point.sum,
+				BucketCounts:      bucketCounts,
+				ExplicitBounds:    DefaultOTLPHistogramBoundaries,
+			})
+		}
+		metrics = append(metrics, otlpMetric{Name: name, Unit: otlpUnitSeconds, Histogram: histogram})
+	}
+
+	for _, name := range sortedMetricNames(c.summaries) {
+		summary := &otlpSummary{}
+		for _, point := range sortedDataPoints(c.summaries[name]) {
+			summary.DataPoints = append(summary.DataPoints, otlpSummaryDataPoint{
+				Attributes:        otlpAttributes(point.labels),
+				StartTimeUnixNano: startTime,
+				TimeUnixNano:      now,
+				Count:             strconv.FormatUint(point.count, 10),
+				Sum:               point.sum,
+				QuantileValues:    []otlpQuantileValue{{Quantile: 0, Value: point.min}, {Quantile: 1, Value: point.max}},
+			})
+		}
+		metrics = append(metrics, otlpMetric{Name: name, Summary: summary})
+	}
+
+	var resourceAttributes []otlpKeyValue
+	if c.serviceName != "" {
+		resourceAttributes = otlpAttributes(map[string]string{otlpServiceNameKey: c.serviceName})
+	}
+
+	return &otlpMetricsRequest{ResourceMetrics: []otlpResourceMetrics{{
+		Resource:     otlpResource{Attributes: resourceAttributes},
+		ScopeMetrics: []otlpScopeMetrics{{Scope: otlpScope{Name: otlpScopeName}, Metrics: metrics}},
+	}}}
+}
+
+func sortedMetricNames(metrics map[string]map[string]*otlpDataPoint) []string {
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func sortedDataPoints(points map[string]*otlpDataPoint) []*otlpDataPoint {
+	keys := make([]string, 0, len(points))
+	for key := range points {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make([]*otlpDataPoint, len(keys))
+	for i, key := range keys {
+		result[i] = points[key]
+	}
+
+	return result
+}
+
+func otlpAttributes(labels map[string]string) []otlpKeyValue {
+	attributes := make([]otlpKeyValue, 0, len(labels))
+	for _, k := range labelset.Keys(labels) {
+		attributes = append(attributes, otlpKeyValue{Key: k, Value: otlpAnyValue{StringValue: labels[k]}})
+	}
+
+	return attributes
+}
+
+// Export sends the current state of all metrics to OTLP receiver
+func (c *OTLP) Export() error {
+	request := c.buildRequest()
+	if len(request.ResourceMetrics[0].ScopeMetrics[0].Metrics) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Post(c.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("OTLP receiver responded with unexpected status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// BuildTimer builds timer to track metric timings
+func (c *OTLP) BuildTimer() timer.Timer {
+	return &timer.Memory{}
+}
+
+// Close stops periodical export and exports metrics for the last time
+func (c *OTLP) Close() error {
+	c.Lock()
+	if c.closed {
+		c.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.Unlock()
+
+	close(c.done)
+	c.wg.Wait()
+
+	return c.Export()
+}
+
+// TrackRequest tracks HTTP Request stats
+func (c *OTLP) TrackRequest(r *http.Request, t timer.Timer, success bool) Client {
+	b := bucket.NewHTTPRequestWithDepth(c.httpRequestSection, r, success, c.httpMetricCallback, c.unicode, c.operationDepth)
+	metric := sanitizeRequestMetric(b.Metric())
+	labels := map[string]string{"success": strconv.FormatBool(success), "action": r.Method}
+
+	c.add(metric, 1, labels)
+	c.add(sanitizeRequestMetric(b.MetricTotal()), 1, labels)
+
+	if nil != t {
+		c.observe(metric+otlpHistogramSuffix, t.Finish().Seconds(), labels)
+	}
+
+	return c
+}
+
+// TrackOperation tracks custom operation
+func (c *OTLP) TrackOperation(section string, operation *bucket.MetricOperation, t timer.Timer, success bool) Client {
+	return c.TrackOperationN(section, operation, t, 1, success)
+}
+
+// TrackOperationN tracks custom operation with n diff
+func (c *OTLP) TrackOperationN(section string, operation *bucket.MetricOperation, t timer.Timer, n int, success bool) Client {
+	b := bucket.NewPrometheusWithDepth(section, operation, success, c.unicode, c.operationDepth)
+
+	labels := make(map[string]string, len(operation.Labels)+1)
+	for k, v := range operation.Labels {
+		labels[k] = v
+	}
+	labels["success"] = strconv.FormatBool(success)
+
+	c.add(b.Metric(), n, labels)
+	c.add(b.MetricTotal(), n, labels)
+
+	if nil != t {
+		c.observe(b.Metric()+otlpHistogramSuffix, t.Finish().Seconds(), labels)
+	}
+
+	return c
+}
+
+// TrackMetric tracks custom metric, w/out ok/fail additional sections
+func (c *OTLP) TrackMetric(section string, operation *bucket.MetricOperation) Client {
+	return c.TrackMetricN(section, operation, 1)
+}
+
+// TrackMetricN tracks custom metric with n diff, w/out ok/fail additional sections
+func (c *OTLP) TrackMetricN(section string, operation *bucket.MetricOperation, n int) Client {
+	b := bucket.NewPrometheusWithDepth(section, operation, true, c.unicode, c.operationDepth)
+
+	c.add(b.Metric(), n, operation.Labels)
+	c.add(b.MetricTotal(), n, operation.Labels)
+
+	return c
+}
+
+// TrackState tracks metric absolute value
+func (c *OTLP) TrackState(section string, operation *bucket.MetricOperation, value int) Client {
+	b := bucket.NewPrometheusWithDepth(section, operation, true, c.unicode, c.operationDepth)
+
+	c.set(b.Metric(), value, operation.Labels)
+
+	return c
+}
+
+// TrackStateFloat tracks metric absolute float value
+func (c *OTLP) TrackStateFloat(section string, operation *bucket.MetricOperation, value float64) Client {
+	b := bucket.NewPrometheusWithDepth(section, operation, true, c.unicode, c.operationDepth)
+
+	c.setFloat(b.Metric(), value, operation.Labels)
+
+	return c
+}
+
+// TrackStateAdd increases metric state by delta
+func (c *OTLP) TrackStateAdd(section string, operation *bucket.MetricOperation, delta float64) Client {
+	b := bucket.NewPrometheusWithDepth(section, operation, true, c.unicode, c.operationDepth)
+
+	c.addFloat(b.Metric(), delta, operation.Labels)
+
+	return c
+}
+
+// TrackStateSub decreases metric state by delta
+func (c *OTLP) TrackStateSub(section string, operation *bucket.MetricOperation, delta float64) Client {
+	return c.TrackStateAdd(section, operation, -delta)
+}
+
+// TrackSummary tracks metric value distribution
+func (c *OTLP) TrackSummary(section string, operation *bucket.MetricOperation, value float64) Client {
+	b := bucket.NewPrometheusWithDepth(section, operation, true, c.unicode, c.operationDepth)
+
+	c.summarize(b.Metric(), value, operation.Labels)
+
+	return c
+}
+
+// TrackUnique tracks estimated number of unique values
+func (c *OTLP) TrackUnique(section string, operation *bucket.MetricOperation, value string) Client {
+	b := bucket.NewPrometheusWithDepth(section, operation, true, c.unicode, c.operationDepth)
+
+	c.addUnique(b.Metric(), value, operation.Labels)
+
+	return c
+}
+
+// SetHTTPMetricCallback sets callback handler that allows metric operation alteration for HTTP Request
+func (c *OTLP) SetHTTPMetricCallback(callback bucket.HTTPMetricNameAlterCallback) Client {
+	c.Lock()
+	defer c.Unlock()
+
+	c.httpMetricCallback = callback
+	return c
+}
+
+// GetHTTPMetricCallback gets callback handler that allows metric operation alteration for HTTP Request
+func (c *OTLP) GetHTTPMetricCallback() bucket.HTTPMetricNameAlterCallback {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.httpMetricCallback
+}
+
+// SetHTTPRequestSection sets metric section for HTTP Request metrics
+func (c *OTLP) SetHTTPRequestSection(section string) Client {
+	c.Lock()
+	defer c.Unlock()
+
+	c.httpRequestSection = section
+	return c
+}
+
+// ResetHTTPRequestSection resets metric section for HTTP Request metrics to default value that is "request"
+func (c *OTLP) ResetHTTPRequestSection() Client {
+	return c.SetHTTPRequestSection(bucket.SectionRequest)
+}
+
+// SetOperationDepth sets number of operations in metric names, shorter operations are padded with placeholders
+// and longer ones are truncated, zero value means bucket.MetricOperationsMaxLength
+func (c *OTLP) SetOperationDepth(depth int) Client {
+	c.Lock()
+	defer c.Unlock()
+
+	c.operationDepth = depth
+	return c
+}
+
+// With returns child client that adds given labels to every tracked metric operation
+func (c *OTLP) With(labels map[string]string) Client {
+	return newScoped(c, labels, "")
+}
+
+// WithSection returns child client that prefixes every tracked metric section with the given prefix
+func (c *OTLP) WithSection(prefix string) Client {
+	return newScoped(c, nil, prefix)
+}
+
+// Handler returns metrics endpoint for prometheus backend
+func (c *OTLP) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+}
@@ -0,0 +1,177 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hellofresh/stats-go/bucket"
+	"github.com/hellofresh/stats-go/timer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOTLPReceiver is an in-process OTLP/HTTP receiver that stores received export requests
+type fakeOTLPReceiver struct {
+	*httptest.Server
+	requests []otlpMetricsRequest
+}
+
+func newFakeOTLPReceiver(t *testing.T) *fakeOTLPReceiver {
+	receiver := &fakeOTLPReceiver{}
+	receiver.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/metrics", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var request otlpMetricsRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		receiver.requests = append(receiver.requests, request)
+
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	return receiver
+}
+
+// metrics returns metrics from the last export request by "<type>:<name>" key
+func (r *fakeOTLPReceiver) metrics(t *testing.T) map[string]otlpMetric {
+	require.True(t, len(r.requests) > 0)
+
+	last := r.requests[len(r.requests)-1]
+	require.Equal(t, 1, len(last.ResourceMetrics))
+	require.Equal(t, 1, len(last.ResourceMetrics[0].ScopeMetrics))
+
+	result := make(map[string]otlpMetric)
+	for _, metric := range last.ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		switch {
+		case metric.Sum != nil:
+			result["sum:"+metric.Name] = metric
+		case metric.Gauge != nil:
+			result["gauge:"+metric.Name] = metric
+		case metric.Histogram != nil:
+			result["histogram:"+metric.Name] = metric
+		case metric.Summary != nil:
+			result["summary:"+metric.Name] = metric
+		}
+	}
+
+	return result
+}
+
+func TestOTLP(t *testing.T) {
+	receiver := newFakeOTLPReceiver(t)
+	defer receiver.Close()
+
+	client := NewOTLP(receiver.URL, "ns", "my-service", time.Hour, false)
+
+	operation := bucket.NewMetricOperation("orders", "create").WithLabels(map[string]string{"country": "de"})
+	client.TrackOperation("ordering", operation, timer.NewDuration(20*time.Millisecond), true)
+	client.TrackOperationN("ordering", operation, timer.NewDuration(2*time.Second), 2, true)
+	client.TrackMetricN("ordering", bucket.NewMetricOperation("orders", "create"), 5)
+	client.TrackState("ordering", bucket.NewMetricOperation("orders", "pending"), 42)
+	client.TrackSummary("ordering", bucket.NewMetricOperation("orders", "size"), 3)
+	client.TrackSummary("ordering", bucket.NewMetricOperation("orders", "size"), 1.5)
+	client.TrackState("ordering", bucket.NewMetricOperation("orders", "inflight"), 2)
+	client.TrackStateAdd("ordering", bucket.NewMetricOperation("orders", "inflight"), 1.5)
+	client.TrackStateSub("ordering", bucket.NewMetricOperation("orders", "inflight"), 0.25)
+	client.TrackStateFloat("ordering", bucket.NewMetricOperation("orders", "ratio"), 0.75)
+	for _, customer := range []string{"c1", "c2", "c1", "c3"} {
+		client.TrackUnique("ordering", bucket.NewMetricOperation("customers"), customer)
+	}
+	require.NoError(t, client.Close())
+	// second close is no-op
+	require.NoError(t, client.Close())
+
+	require.Equal(t, 1, len(receiver.requests))
+	assert.Equal(t, []otlpKeyValue{{Key: "service.name", Value: otlpAnyValue{StringValue: "my-service"}}}, receiver.requests[0].ResourceMetrics[0].Resource.Attributes)
+
+	metrics := receiver.metrics(t)
+
+	sum := metrics["sum:ns_ordering_orders_create"].Sum
+	require.NotNil(t, sum)
+	assert.True(t, sum.IsMonotonic)
+	assert.Equal(t, otlpAggregationTemporalityCumulative, sum.AggregationTemporality)
+	require.Equal(t, 2, len(sum.DataPoints))
+	assert.Equal(t, "5", sum.DataPoints[0].AsInt)
+	assert.Equal(t, 0, len(sum.DataPoints[0].Attributes))
+	assert.Equal(t, "3", sum.DataPoints[1].AsInt)
+	assert.Equal(t, []otlpKeyValue{
+		{Key: "country", Value: otlpAnyValue{StringValue: "de"}},
+		{Key: "success", Value: otlpAnyValue{StringValue: "true"}},
+	}, sum.DataPoints[1].Attributes)
+
+	total := metrics["sum:ns_total_ordering"].Sum
+	require.NotNil(t, total)
+	assert.Equal(t, 2, len(total.DataPoints))
+
+	gauge := metrics["gauge:ns_ordering_orders_pending"].Gauge
+	require.NotNil(t, gauge)
+	require.Equal(t, 1, len(gauge.DataPoints))
+	assert.Equal(t, "42", gauge.DataPoints[0].AsInt)
+
+	inflight := metrics["gauge:ns_ordering_orders_inflight"].Gauge
+	require.NotNil(t, inflight)
+	require.NotNil(t, inflight.DataPoints[0].AsDouble)
+	assert.Equal(t, "", inflight.DataPoints[0].AsInt)
+	assert.Equal(t, 3.25, *inflight.DataPoints[0].AsDouble)
+
+	ratio := metrics["gauge:ns_ordering_orders_ratio"].Gauge
+	require.NotNil(t, ratio)
+	require.NotNil(t, ratio.DataPoints[0].AsDouble)
+	assert.Equal(t, 0.75, *ratio.DataPoints[0].AsDouble)
+
+	customers := metrics["gauge:ns_ordering_customers"].Gauge
+	require.NotNil(t, customers)
+	assert.Equal(t, "3", customers.DataPoints[0].AsInt)
+
+	summary := metrics["summary:ns_ordering_orders_size"].Summary
+	require.NotNil(t, summary)
+	require.Equal(t, 1, len(summary.DataPoints))
+	assert.Equal(t, "2", summary.DataPoints[0].Count)
+	assert.Equal(t, 4.5, summary.DataPoints[0].Sum)
+	assert.Equal(t, []otlpQuantileValue{{Quantile: 0, Value: 1.5}, {Quantile: 1, Value: 3}}, summary.DataPoints[0].QuantileValues)
+
+	// histogram name does not clash with operation counter sum
+	assert.Nil(t, metrics["histogram:ns_ordering_orders_create"].Histogram)
+	histogramMetric := metrics["histogram:ns_ordering_orders_create_seconds"]
+	require.NotNil(t, histogramMetric.Histogram)
+	assert.Equal(t, "s", histogramMetric.Unit)
+	require.Equal(t, 1, len(histogramMetric.Histogram.DataPoints))
+
+	point := histogramMetric.Histogram.DataPoints[0]
+	assert.Equal(t, "2", point.Count)
+	assert.InDelta(t, 2.02, point.Sum, 0.0001)
+	assert.Equal(t, DefaultOTLPHistogramBoundaries, point.ExplicitBounds)
+	assert.Equal(t, []string{"0", "0", "1", "0", "0", "0", "0", "0", "1", "0", "0", "0"}, point.BucketCounts)
+}
+
+func TestOTLP_TrackRequest(t *testing.T) {
+	receiver := newFakeOTLPReceiver(t)
+	defer receiver.Close()
+
+	client := NewOTLP(receiver.URL, "ns", "", time.Hour, false)
+	client.TrackRequest(httptest.NewRequest(http.MethodGet, "/users/13", nil), timer.NewDuration(time.Second), true)
+	require.NoError(t, client.Close())
+
+	metrics := receiver.metrics(t)
+	require.NotNil(t, metrics["sum:ns_request_get_users_13"].Sum)
+	assert.Nil(t, metrics["histogram:ns_request_get_users_13"].Histogram)
+	require.NotNil(t, metrics["histogram:ns_request_get_users_13_seconds"].Histogram)
+	assert.Equal(t, "1", metrics["histogram:ns_request_get_users_13_seconds"].Histogram.DataPoints[0].Count)
+}
+
+func TestOTLP_ExportError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewOTLP(server.URL, "", "", time.Hour, false)
+	assert.NoError(t, client.Export())
+
+	client.TrackMetric("section", bucket.NewMetricOperation("foo"))
+	assert.Error(t, client.Export())
+	assert.Error(t, client.Close())
+}
@@ -1,6 +1,8 @@
 package client
 
 import (
+	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
@@ -9,12 +11,97 @@ import (
 
 	"github.com/hellofresh/stats-go/bucket"
 	"github.com/hellofresh/stats-go/incrementer"
+	"github.com/hellofresh/stats-go/internal/labelset"
+	"github.com/hellofresh/stats-go/log"
 	"github.com/hellofresh/stats-go/state"
 	"github.com/hellofresh/stats-go/timer"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
 )
 
+// DefaultPushInterval is a default interval Prometheus client pushes metrics to Pushgateway with
+const DefaultPushInterval = 15 * time.Second
+
+// DefaultSummaryObjectives are default quantiles with their absolute errors tracked by summaries
+var DefaultSummaryObjectives = map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001}
+
+// OverflowLabelValue is a label value all labels of the series are set to when metric reaches
+// label values combinations limit set with WithMaxLabelCardinality
+const OverflowLabelValue = "__overflow__"
+
+// PrometheusOption is a function that configures Prometheus client
+type PrometheusOption func(*Prometheus)
+
+// WithPushGateway enables push mode - all collected metrics are pushed to Pushgateway with given url
+// under the given job name periodically and on client close, that is useful for batch jobs and
+// short-living processes that exit before being scraped
+func WithPushGateway(url string, job string, interval time.Duration) PrometheusOption {
+	return func(c *Prometheus) {
+		if interval <= 0 {
+			interval = DefaultPushInterval
+		}
+
+		c.pusher = push.New(url, job)
+		c.pushInterval = interval
+	}
+}
+
+// WithRegistry makes client register its histograms with the given registerer and expose and push metrics
+// collected by the given gatherer, usually both are the same *prometheus.Registry instance.
+// Incrementer and state factories passed to the client should use the same registerer,
+// see incrementer.NewPrometheusIncrementerFactoryWithRegisterer and state.NewPrometheusStateFactoryWithRegisterer.
+func WithRegistry(registerer prometheus.Registerer, gatherer prometheus.Gatherer) PrometheusOption {
+	return func(c *Prometheus) {
+		c.registerer = registerer
+		c.gatherer = gatherer
+	}
+}
+
+// WithDefaultHistogramBuckets sets buckets, upper bounds in seconds, for all operation timing histograms
+// that have no section specific buckets set with WithHistogramBuckets, prometheus.DefBuckets are used by default
+func WithDefaultHistogramBuckets(buckets []float64) PrometheusOption {
+	return func(c *Prometheus) {
+		c.defaultBuckets = buckets
+	}
+}
+
+// WithHistogramBuckets sets buckets, upper bounds in seconds, for operation timing histograms of the given section
+func WithHistogramBuckets(section string, buckets []float64) PrometheusOption {
+	return func(c *Prometheus) {
+		c.sectionBuckets[section] = buckets
+	}
+}
+
+// WithSummaryObjectives sets quantiles with their absolute errors tracked by summaries created with TrackSummary
+func WithSummaryObjectives(objectives map[float64]float64) PrometheusOption {
+	return func(c *Prometheus) {
+		c.summaryObjectives = objectives
+	}
+}
+
+// WithMaxLabelCardinality limits the number of distinct label values combinations tracked per metric,
+// once the limit is reached new combinations are tracked in a single series with all label values set
+// to OverflowLabelValue, that is logged once per metric, and distinct rejected combinations are approximately counted
+// in "<namespace>_rejected_series_total" counter with "metric" label using fixed amount of memory per metric.
+// Zero limit, that is the default, means no limit.
+func WithMaxLabelCardinality(limit int) PrometheusOption {
+	return func(c *Prometheus) {
+		c.maxLabelCardinality = limit
+	}
+}
+
+// NewPrometheusRegistry builds new dedicated prometheus registry with go runtime and process collectors registered
+func NewPrometheusRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+	)
+
+	return registry
+}
+
 // Prometheus is Client implementation for prometheus
 type Prometheus struct {
 	sync.Mutex
@@ -22,6 +109,7 @@ type Prometheus struct {
 	unicode            bool
 	httpMetricCallback bucket.HTTPMetricNameAlterCallback
 	httpRequestSection string
+	operationDepth     int
 
 	namespace  string
 	incFactory incrementer.Factory
@@ -30,10 +118,29 @@ type Prometheus struct {
 	increments map[string]incrementer.Incrementer
 	states     map[string]state.State
 	histograms map[string]*prometheus.HistogramVec
+	summaries  map[string]*prometheus.SummaryVec
+	uniques    map[string]*hyperLogLog
+
+	registerer prometheus.Registerer
+	gatherer   prometheus.Gatherer
+
+	defaultBuckets    []float64
+	sectionBuckets    map[string][]float64
+	summaryObjectives map[float64]float64
+
+	maxLabelCardinality int
+	labelSeries         map[string]*labelSeries
+	rejectedSeries      *prometheus.CounterVec
+
+	pusher       *push.Pusher
+	pushInterval time.Duration
+	done         chan struct{}
+	wg           sync.WaitGroup
+	closed       bool
 }
 
 // NewPrometheus builds and returns new Prometheus instance
-func NewPrometheus(namespace string, incFactory incrementer.Factory, stFactory state.Factory) *Prometheus {
+func NewPrometheus(namespace string, incFactory incrementer.Factory, stFactory state.Factory, opts ...PrometheusOption) *Prometheus {
 	client := &Prometheus{
 		namespace:  namespace,
 		incFactory: incFactory,
@@ -41,18 +148,162 @@ func NewPrometheus(namespace string, incFactory incrementer.Factory, stFactory s
 		increments: make(map[string]incrementer.Incrementer),
 		states:     make(map[string]state.State),
 		histograms: make(map[string]*prometheus.HistogramVec),
+		summaries:  make(map[string]*prometheus.SummaryVec),
+		uniques:    make(map[string]*hyperLogLog),
+		registerer: prometheus.DefaultRegisterer,
+		gatherer:   prometheus.DefaultGatherer,
+		done:       make(chan struct{}),
+
+		sectionBuckets:    make(map[string][]float64),
+		summaryObjectives: DefaultSummaryObjectives,
+		labelSeries:       make(map[string]*labelSeries),
+	}
+
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	if client.maxLabelCardinality > 0 {
+		client.registerRejectedSeries()
+	}
+
+	if client.pusher != nil {
+		client.pusher.Gatherer(client.gatherer)
+
+		client.wg.Add(1)
+		go client.pushPeriodically()
 	}
+
 	return client
 }
 
+func (c *Prometheus) pushPeriodically() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.pushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.pusher.Push(); err != nil {
+				log.Log("An error occurred while pushing metrics to Pushgateway", nil, err)
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
 // BuildTimer builds timer to track metric timings
 func (c *Prometheus) BuildTimer() timer.Timer {
 	return &timer.Memory{}
 }
 
-// Close closes underlying client connection if any
+// Close closes underlying client connection if any, in push mode metrics are pushed for the last time
 func (c *Prometheus) Close() error {
-	return nil
+	if c.pusher == nil {
+		return nil
+	}
+
+	c.Lock()
+	if c.closed {
+		c.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.Unlock()
+
+	close(c.done)
+	c.wg.Wait()
+
+	return c.pusher.Push()
+}
+
+// labelSeries is a set of label values combinations tracked for a metric and an estimated set of combinations
+// rejected by cardinality limit, so that every rejected combination is counted once using fixed amount of memory
+type labelSeries struct {
+	values   map[string]struct{}
+	rejected *hyperLogLog
+	counted  uint64
+}
+
+// registerRejectedSeries registers counter of label values combinations rejected by cardinality limit
+func (c *Prometheus) registerRejectedSeries() {
+	name := "rejected_series_total"
+	if c.namespace != "" {
+		name = c.prepareMetric(name)
+	}
+
+	rejected := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: name,
+		Help: "Number of label values combinations tracked as overflow series due to cardinality limit",
+	}, []string{"metric"})
+
+	collector, err := c.register(rejected)
+	if err != nil {
+		log.Log("An error occurred while registering prometheus rejected series counter", nil, err)
+		return
+	}
+
+	if rejected, ok := collector.(*prometheus.CounterVec); ok {
+		c.rejectedSeries = rejected
+	}
+}
+
+// limitLabels returns labels as is if metric has not reached label values combinations limit yet
+// or the combination was already tracked, otherwise returns overflow labels with the same names
+func (c *Prometheus) limitLabels(metric string, labels map[string]string) map[string]string {
+	if c.maxLabelCardinality <= 0 || len(labels) == 0 {
+		return labels
+	}
+
+	keys := labelset.Keys(labels)
+	series := ""
+	for _, k := range keys {
+		series += k + "\xff" + labels[k] + "\xff"
+	}
+
+	c.Lock()
+	seen, ok := c.labelSeries[metric]
+	if !ok {
+		seen = &labelSeries{values: make(map[string]struct{})}
+		c.labelSeries[metric] = seen
+	}
+	if _, ok := seen.values[series]; ok || len(seen.values) < c.maxLabelCardinality {
+		seen.values[series] = struct{}{}
+		c.Unlock()
+		return labels
+	}
+	first := seen.rejected == nil
+	if first {
+		seen.rejected = newHyperLogLog()
+	}
+	var delta uint64
+	if seen.rejected.Add(series) {
+		if estimate := uint64(math.Round(seen.rejected.Estimate())); estimate > seen.counted {
+			delta = estimate - seen.counted
+			seen.counted = estimate
+		}
+	}
+	c.Unlock()
+
+	if first {
+		log.Log("Prometheus metric reached label values combinations limit, new combinations are tracked as overflow series", map[string]interface{}{
+			"metric": metric,
+			"limit":  c.maxLabelCardinality,
+		}, nil)
+	}
+	if delta > 0 && c.rejectedSeries != nil {
+		c.rejectedSeries.WithLabelValues(metric).Add(float64(delta))
+	}
+
+	overflow := make(map[string]string, len(labels))
+	for _, k := range keys {
+		overflow[k] = OverflowLabelValue
+	}
+
+	return overflow
 }
 
 // prepareMetric adds namespace to metric
@@ -89,43 +340,122 @@ func (c *Prometheus) getState(name string) state.State {
 }
 
 // getHistogram creates new histogram instance from prometheus library if it was not created before or gets existing
-func (c *Prometheus) getHistogram(name string, labels ...map[string]string) *prometheus.HistogramVec {
-	var keys []string
-	var values []string
+func (c *Prometheus) getHistogram(section, name string, labels ...map[string]string) (*prometheus.HistogramVec, error) {
+	keys := labelset.Keys(labels[0])
+
+	c.Lock()
+	defer c.Unlock()
+
+	if h, ok := c.histograms[name]; ok {
+		return h, nil
+	}
 
-	for key, value := range labels[0] {
-		keys = append(keys, key)
-		values = append(values, value)
+	buckets, ok := c.sectionBuckets[section]
+	if !ok {
+		buckets = c.defaultBuckets
+	}
+
+	h := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    name + "_seconds",
+		Help:    " ",
+		Buckets: buckets,
+	}, keys)
+	collector, err := c.register(h)
+	if err != nil {
+		return nil, err
+	}
+
+	h, ok = collector.(*prometheus.HistogramVec)
+	if !ok {
+		return nil, fmt.Errorf("metric %q is already registered with different type", name)
 	}
+	c.histograms[name] = h
+
+	return h, nil
+}
+
+// getSummary creates new summary instance from prometheus library if it was not created before or gets existing
+func (c *Prometheus) getSummary(name string, labels map[string]string) (*prometheus.SummaryVec, error) {
+	keys := labelset.Keys(labels)
 
 	c.Lock()
 	defer c.Unlock()
 
-	if _, ok := c.histograms[name]; !ok {
-		c.histograms[name] = prometheus.NewHistogramVec(prometheus.HistogramOpts{
-			Name: name + "_seconds",
-			Help: " ",
-		}, keys)
-		prometheus.Register(c.histograms[name])
+	if s, ok := c.summaries[name]; ok {
+		return s, nil
+	}
+
+	s := prometheus.NewSummaryVec(prometheus.SummaryOpts{
+		Name:       name,
+		Help:       " ",
+		Objectives: c.summaryObjectives,
+	}, keys)
+
+	collector, err := c.register(s)
+	if err != nil {
+		return nil, err
 	}
-	return c.histograms[name]
+
+	s, ok := collector.(*prometheus.SummaryVec)
+	if !ok {
+		return nil, fmt.Errorf("metric %q is already registered with different type", name)
+	}
+	c.summaries[name] = s
+
+	return s, nil
 }
 
-// TrackRequest tracks HTTP Request stats
-func (c *Prometheus) TrackRequest(r *http.Request, t timer.Timer, success bool) Client {
-	b := bucket.NewHTTPRequest(c.httpRequestSection, r, success, c.httpMetricCallback, c.unicode)
-	metric := b.Metric()
-	metricTotal := b.MetricTotal()
+// register registers collector with client registerer, if the same collector is already registered
+// existing one is returned
+func (c *Prometheus) register(collector prometheus.Collector) (prometheus.Collector, error) {
+	if err := c.registerer.Register(collector); err != nil {
+		are, ok := err.(prometheus.AlreadyRegisteredError)
+		if !ok {
+			return nil, err
+		}
+
+		return are.ExistingCollector, nil
+	}
+
+	return collector, nil
+}
 
+// observe records duration in seconds in the histogram with the given name, errors are logged as tracking
+// methods have no way to return them
+func (c *Prometheus) observe(section, name string, labels map[string]string, t timer.Timer) {
+	h, err := c.getHistogram(section, name, labels)
+	if err != nil {
+		log.Log("An error occurred while registering prometheus histogram", map[string]interface{}{"metric": name}, err)
+		return
+	}
+
+	observer, err := h.GetMetricWith(prometheus.Labels(labels))
+	if err != nil {
+		log.Log("An error occurred while observing prometheus histogram", map[string]interface{}{"metric": name}, err)
+		return
+	}
+
+	observer.Observe(t.Finish().Seconds())
+}
+
+// routePatternReplacer removes route pattern wildcard braces and asterisks that are not allowed in prometheus metric names
+var routePatternReplacer = strings.NewReplacer("{", "", "}", "", "*", "")
+
+// sanitizeRequestMetric converts HTTP Request bucket metric name to prometheus compatible form
+func sanitizeRequestMetric(metric string) string {
 	metric = strings.Replace(metric, "-.", "", -1)
 	metric = strings.Replace(metric, ".-", "", -1)
 	metric = strings.Replace(metric, "-", "", -1)
-	metric = strings.Replace(metric, ".", "_", -1)
+	metric = routePatternReplacer.Replace(metric)
+	return strings.Replace(metric, ".", "_", -1)
+}
 
-	metricTotal = strings.Replace(metricTotal, "-.", "", -1)
-	metricTotal = strings.Replace(metricTotal, ".-", "", -1)
-	metricTotal = strings.Replace(metricTotal, "-", "", -1)
-	metricTotal = strings.Replace(metricTotal, ".", "_", -1)
+// TrackRequest tracks HTTP Request stats, request duration is tracked in "<namespace>_<section>_duration_seconds"
+// histogram with "method", "route" and "success" labels
+func (c *Prometheus) TrackRequest(r *http.Request, t timer.Timer, success bool) Client {
+	b := bucket.NewHTTPRequestWithDepth(c.httpRequestSection, r, success, c.httpMetricCallback, c.unicode, c.operationDepth)
+	metric := sanitizeRequestMetric(b.Metric())
+	metricTotal := sanitizeRequestMetric(b.MetricTotal())
 
 	metricInc := c.getIncrementer(metric)
 	metricTotalInc := c.getIncrementer(metricTotal)
@@ -134,15 +464,26 @@ func (c *Prometheus) TrackRequest(r *http.Request, t timer.Timer, success bool)
 
 	metric = c.prepareMetric(metric)
 	metricTotal = c.prepareMetric(metricTotal)
-	metricInc.Increment(metric, labels)
-	metricTotalInc.Increment(metricTotal, labels)
+	metricInc.Increment(metric, c.limitLabels(metric, labels))
+	metricTotalInc.Increment(metricTotal, c.limitLabels(metricTotal, labels))
+
+	if nil != t {
+		section := c.httpRequestSection
+		if section == "" {
+			section = bucket.SectionRequest
+		}
+
+		durationMetric := c.prepareMetric(sanitizeRequestMetric(section) + "_duration")
+		durationLabels := map[string]string{"success": strconv.FormatBool(success), "method": r.Method, "route": b.Route()}
+		c.observe(section, durationMetric, c.limitLabels(durationMetric, durationLabels), t)
+	}
 
 	return c
 }
 
 // TrackOperation tracks custom operation
 func (c *Prometheus) TrackOperation(section string, operation *bucket.MetricOperation, t timer.Timer, success bool) Client {
-	b := bucket.NewPrometheus(section, operation, success, c.unicode)
+	b := bucket.NewPrometheusWithDepth(section, operation, success, c.unicode, c.operationDepth)
 
 	if operation.Labels == nil {
 		operation.Labels = map[string]string{"success": strconv.FormatBool(success)}
@@ -153,13 +494,8 @@ func (c *Prometheus) TrackOperation(section string, operation *bucket.MetricOper
 	c.TrackMetric(section, operation)
 
 	if nil != t {
-		var values []string
-
-		h := c.getHistogram(c.prepareMetric(b.Metric()), operation.Labels)
-		for _, value := range operation.Labels {
-			values = append(values, value)
-		}
-		h.WithLabelValues(values...).Observe(float64(t.Finish() / time.Millisecond))
+		metric := c.prepareMetric(b.Metric())
+		c.observe(section, metric, c.limitLabels(metric, operation.Labels), t)
 	}
 
 	return c
@@ -167,7 +503,7 @@ func (c *Prometheus) TrackOperation(section string, operation *bucket.MetricOper
 
 // TrackOperationN tracks custom operation with n diff
 func (c *Prometheus) TrackOperationN(section string, operation *bucket.MetricOperation, t timer.Timer, n int, success bool) Client {
-	b := bucket.NewPrometheus(section, operation, success, c.unicode)
+	b := bucket.NewPrometheusWithDepth(section, operation, success, c.unicode, c.operationDepth)
 
 	if operation.Labels == nil {
 		operation.Labels = map[string]string{"success": strconv.FormatBool(success)}
@@ -178,13 +514,8 @@ func (c *Prometheus) TrackOperationN(section string, operation *bucket.MetricOpe
 	c.TrackMetricN(section, operation, n)
 
 	if nil != t {
-		var values []string
-
-		h := c.getHistogram(c.prepareMetric(b.Metric()), operation.Labels)
-		for _, value := range operation.Labels {
-			values = append(values, value)
-		}
-		h.WithLabelValues(values...).Observe(float64(t.Finish() / time.Millisecond))
+		metric := c.prepareMetric(b.Metric())
+		c.observe(section, metric, c.limitLabels(metric, operation.Labels), t)
 	}
 
 	return c
@@ -192,7 +523,7 @@ func (c *Prometheus) TrackOperationN(section string, operation *bucket.MetricOpe
 
 // TrackMetric tracks custom metric, w/out ok/fail additional sections
 func (c *Prometheus) TrackMetric(section string, operation *bucket.MetricOperation) Client {
-	b := bucket.NewPrometheus(section, operation, true, c.unicode)
+	b := bucket.NewPrometheusWithDepth(section, operation, true, c.unicode, c.operationDepth)
 	metric := b.Metric()
 	metricTotal := b.MetricTotal()
 
@@ -201,15 +532,15 @@ func (c *Prometheus) TrackMetric(section string, operation *bucket.MetricOperati
 
 	metric = c.prepareMetric(metric)
 	metricTotal = c.prepareMetric(metricTotal)
-	metricInc.Increment(metric, operation.Labels)
-	metricTotalInc.Increment(metricTotal, operation.Labels)
+	metricInc.Increment(metric, c.limitLabels(metric, operation.Labels))
+	metricTotalInc.Increment(metricTotal, c.limitLabels(metricTotal, operation.Labels))
 
 	return c
 }
 
 // TrackMetricN tracks custom metric with n diff, w/out ok/fail additional sections
 func (c *Prometheus) TrackMetricN(section string, operation *bucket.MetricOperation, n int) Client {
-	b := bucket.NewPrometheus(section, operation, true, c.unicode)
+	b := bucket.NewPrometheusWithDepth(section, operation, true, c.unicode, c.operationDepth)
 	metric := b.Metric()
 	metricTotal := b.MetricTotal()
 
@@ -218,25 +549,123 @@ func (c *Prometheus) TrackMetricN(section string, operation *bucket.MetricOperat
 
 	metric = c.prepareMetric(metric)
 	metricTotal = c.prepareMetric(metricTotal)
-	metricInc.IncrementN(metric, n, operation.Labels)
-	metricTotalInc.IncrementN(metricTotal, n, operation.Labels)
+	metricInc.IncrementN(metric, n, c.limitLabels(metric, operation.Labels))
+	metricTotalInc.IncrementN(metricTotal, n, c.limitLabels(metricTotal, operation.Labels))
 
 	return c
 }
 
 // TrackState tracks metric absolute value
 func (c *Prometheus) TrackState(section string, operation *bucket.MetricOperation, value int) Client {
-	b := bucket.NewPrometheus(section, operation, true, c.unicode)
+	b := bucket.NewPrometheusWithDepth(section, operation, true, c.unicode, c.operationDepth)
+	metric := b.Metric()
+
+	st := c.getState(metric)
+
+	metric = c.prepareMetric(metric)
+	st.Set(metric, value, c.limitLabels(metric, operation.Labels))
+
+	return c
+}
+
+// TrackStateFloat tracks metric absolute float value
+func (c *Prometheus) TrackStateFloat(section string, operation *bucket.MetricOperation, value float64) Client {
+	b := bucket.NewPrometheusWithDepth(section, operation, true, c.unicode, c.operationDepth)
+	metric := b.Metric()
+
+	st := c.getState(metric)
+
+	metric = c.prepareMetric(metric)
+	st.SetFloat(metric, value, c.limitLabels(metric, operation.Labels))
+
+	return c
+}
+
+// TrackStateAdd increases metric state by delta
+func (c *Prometheus) TrackStateAdd(section string, operation *bucket.MetricOperation, delta float64) Client {
+	b := bucket.NewPrometheusWithDepth(section, operation, true, c.unicode, c.operationDepth)
 	metric := b.Metric()
 
 	st := c.getState(metric)
 
 	metric = c.prepareMetric(metric)
-	st.Set(metric, value, operation.Labels)
+	st.Add(metric, delta, c.limitLabels(metric, operation.Labels))
 
 	return c
 }
 
+// TrackStateSub decreases metric state by delta
+func (c *Prometheus) TrackStateSub(section string, operation *bucket.MetricOperation, delta float64) Client {
+	b := bucket.NewPrometheusWithDepth(section, operation, true, c.unicode, c.operationDepth)
+	metric := b.Metric()
+
+	st := c.getState(metric)
+
+	metric = c.prepareMetric(metric)
+	st.Sub(metric, delta, c.limitLabels(metric, operation.Labels))
+
+	return c
+}
+
+// TrackSummary tracks metric value distribution in "<namespace>_<section>_<operations>" summary
+func (c *Prometheus) TrackSummary(section string, operation *bucket.MetricOperation, value float64) Client {
+	b := bucket.NewPrometheusWithDepth(section, operation, true, c.unicode, c.operationDepth)
+	name := c.prepareMetric(b.Metric())
+
+	labels := c.limitLabels(name, operation.Labels)
+
+	s, err := c.getSummary(name, labels)
+	if err != nil {
+		log.Log("An error occurred while registering prometheus summary", map[string]interface{}{"metric": name}, err)
+		return c
+	}
+
+	observer, err := s.GetMetricWith(prometheus.Labels(labels))
+	if err != nil {
+		log.Log("An error occurred while observing prometheus summary", map[string]interface{}{"metric": name}, err)
+		return c
+	}
+
+	observer.Observe(value)
+
+	return c
+}
+
+// TrackUnique tracks estimated number of unique values observed since client start
+// in "<namespace>_<section>_<operations>" gauge, values are counted with HyperLogLog estimator
+func (c *Prometheus) TrackUnique(section string, operation *bucket.MetricOperation, value string) Client {
+	b := bucket.NewPrometheusWithDepth(section, operation, true, c.unicode, c.operationDepth)
+	metric := b.Metric()
+
+	st := c.getState(metric)
+
+	metric = c.prepareMetric(metric)
+	labels := c.limitLabels(metric, operation.Labels)
+	st.SetFloat(metric, math.Round(c.addUnique(metric, labels, value)), labels)
+
+	return c
+}
+
+// addUnique adds value to the set for metric and labels and returns estimated set size
+func (c *Prometheus) addUnique(metric string, labels map[string]string, value string) float64 {
+	key := metric
+	for _, k := range labelset.Keys(labels) {
+		key += "\xff" + k + "=" + labels[k]
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	h, ok := c.uniques[key]
+	if !ok {
+		h = newHyperLogLog()
+		c.uniques[key] = h
+	}
+	h.Add(value)
+
+	return h.Estimate()
+}
+
 // SetHTTPMetricCallback sets callback handler that allows metric operation alteration for HTTP Request
 func (c *Prometheus) SetHTTPMetricCallback(callback bucket.HTTPMetricNameAlterCallback) Client {
 	c.Lock()
@@ -265,7 +694,27 @@ func (c *Prometheus) ResetHTTPRequestSection() Client {
 	return c.SetHTTPRequestSection(bucket.SectionRequest)
 }
 
+// SetOperationDepth sets number of operations in metric names, shorter operations are padded with placeholders
+// and longer ones are truncated, zero value means bucket.MetricOperationsMaxLength
+func (c *Prometheus) SetOperationDepth(depth int) Client {
+	c.Lock()
+	defer c.Unlock()
+
+	c.operationDepth = depth
+	return c
+}
+
+// With returns child client that adds given labels to every tracked metric operation
+func (c *Prometheus) With(labels map[string]string) Client {
+	return newScoped(c, labels, "")
+}
+
+// WithSection returns child client that prefixes every tracked metric section with the given prefix
+func (c *Prometheus) WithSection(prefix string) Client {
+	return newScoped(c, nil, prefix)
+}
+
 // Handler returns metrics endpoint for prometheus backend
 func (c *Prometheus) Handler() http.Handler {
-	return promhttp.Handler()
+	return promhttp.InstrumentMetricHandler(c.registerer, promhttp.HandlerFor(c.gatherer, promhttp.HandlerOpts{}))
 }
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	linearBucketsPrefix      = "linear:"
+	exponentialBucketsPrefix = "exponential:"
+)
+
+// ErrInvalidHistogramBuckets is an error returned when histogram buckets definition can not be parsed
+var ErrInvalidHistogramBuckets = errors.New("invalid histogram buckets definition")
+
+// ParseHistogramBuckets parses histogram buckets definition, the following formats are supported:
+// "0.005,0.01,0.1,1" - explicit list of upper bounds in increasing order,
+// "linear:<start>,<width>,<count>" - see prometheus.LinearBuckets,
+// "exponential:<start>,<factor>,<count>" - see prometheus.ExponentialBuckets
+func ParseHistogramBuckets(definition string) ([]float64, error) {
+	switch {
+	case strings.HasPrefix(definition, linearBucketsPrefix):
+		start, width, count, err := parseBucketsGenerator(strings.TrimPrefix(definition, linearBucketsPrefix))
+		if err != nil || width <= 0 {
+			return nil, ErrInvalidHistogramBuckets
+		}
+		return prometheus.LinearBuckets(start, width, count), nil
+	case strings.HasPrefix(definition, exponentialBucketsPrefix):
+		start, factor, count, err := parseBucketsGenerator(strings.TrimPrefix(definition, exponentialBucketsPrefix))
+		if err != nil || start <= 0 || factor <= 1 {
+			return nil, ErrInvalidHistogramBuckets
+		}
+		return prometheus.ExponentialBuckets(start, factor, count), nil
+	}
+
+	var buckets []float64
+	for _, part := range strings.Split(definition, ",") {
+		bound, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, ErrInvalidHistogramBuckets
+		}
+		if len(buckets) > 0 && bound <= buckets[len(buckets)-1] {
+			return nil, ErrInvalidHistogramBuckets
+		}
+		buckets = append(buckets, bound)
+	}
+
+	return buckets, nil
+}
+
+// parseBucketsGenerator parses "<float>,<float>,<int>" generator arguments
+func parseBucketsGenerator(args string) (float64, float64, int, error) {
+	parts := strings.Split(args, ",")
+	if len(parts) != 3 {
+		return 0, 0, 0, ErrInvalidHistogramBuckets
+	}
+
+	first, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	second, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(parts[2]))
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if count < 1 {
+		return 0, 0, 0, ErrInvalidHistogramBuckets
+	}
+
+	return first, second, count, nil
+}
@@ -0,0 +1,41 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHistogramBuckets(t *testing.T) {
+	dataProvider := []struct {
+		Definition string
+		Buckets    []float64
+		Err        error
+	}{
+		{"0.005, 0.01,0.1,1", []float64{0.005, 0.01, 0.1, 1}, nil},
+		{"linear:0.1,0.2,3", []float64{0.1, 0.3, 0.5}, nil},
+		{"exponential:0.001,10,4", []float64{0.001, 0.01, 0.1, 1}, nil},
+		{"", nil, ErrInvalidHistogramBuckets},
+		{"0.1,foo", nil, ErrInvalidHistogramBuckets},
+		{"1,0.1", nil, ErrInvalidHistogramBuckets},
+		{"linear:0.1,0.2", nil, ErrInvalidHistogramBuckets},
+		{"linear:0.1,0,3", nil, ErrInvalidHistogramBuckets},
+		{"linear:0.1,0.2,0", nil, ErrInvalidHistogramBuckets},
+		{"exponential:0,10,4", nil, ErrInvalidHistogramBuckets},
+		{"exponential:0.001,1,4", nil, ErrInvalidHistogramBuckets},
+	}
+
+	for _, data := range dataProvider {
+		buckets, err := ParseHistogramBuckets(data.Definition)
+		assert.Equal(t, data.Err, err, data.Definition)
+		if data.Buckets == nil {
+			assert.Nil(t, buckets, data.Definition)
+			continue
+		}
+
+		assert.Equal(t, len(data.Buckets), len(buckets), data.Definition)
+		for i := range data.Buckets {
+			assert.InDelta(t, data.Buckets[i], buckets[i], 1e-9, data.Definition)
+		}
+	}
+}
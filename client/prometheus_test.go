@@ -1,14 +1,21 @@
 package client
 
 import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/hellofresh/stats-go/bucket"
 	"github.com/hellofresh/stats-go/incrementer"
 	"github.com/hellofresh/stats-go/state"
 	"github.com/hellofresh/stats-go/timer"
 	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Mock incrementer object
@@ -85,6 +92,10 @@ type mockState struct {
 	setMethodNumbers []int
 	setMethodMetrics []string
 	setMethodLabels  []map[string]string
+
+	setFloatMethodValues []float64
+	addMethodDeltas      []float64
+	subMethodDeltas      []float64
 }
 
 func (s *mockState) Set(metric string, n int, labels ...map[string]string) {
@@ -96,6 +107,18 @@ func (s *mockState) Set(metric string, n int, labels ...map[string]string) {
 	}
 }
 
+func (s *mockState) SetFloat(metric string, value float64, labels ...map[string]string) {
+	s.setFloatMethodValues = append(s.setFloatMethodValues, value)
+}
+
+func (s *mockState) Add(metric string, delta float64, labels ...map[string]string) {
+	s.addMethodDeltas = append(s.addMethodDeltas, delta)
+}
+
+func (s *mockState) Sub(metric string, delta float64, labels ...map[string]string) {
+	s.subMethodDeltas = append(s.subMethodDeltas, delta)
+}
+
 // Mock StateFactory object
 type mockStateFactory struct {
 	s *mockState
@@ -319,3 +342,428 @@ func TestPrometheusClient_TrackOperationNAlreadyExists(t *testing.T) {
 	assert.Equal(t, []string{"namespace_section_foo_bar_baz", "namespace_total_section", "namespace_section_foo_bar_baz", "namespace_total_section"}, m.inc.incrementNMethodMetrics)
 	assert.Equal(t, []map[string]string{{"success": "true"}, {"success": "true"}, {"success": "false"}, {"success": "false"}}, m.inc.incrementNMethodLabels)
 }
+
+func TestPrometheusClient_PushGateway(t *testing.T) {
+	var (
+		method string
+		path   string
+		body   []byte
+		pushes int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pushes++
+		method = r.Method
+		path = r.URL.Path
+		body, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	p := NewPrometheus("pushgateway", newMockIncrementerFactory(), newMockStateFactory(), WithPushGateway(server.URL, "batch", time.Hour))
+	p.TrackOperation("section", bucket.NewMetricOperation("foo", "bar", "baz"), timer.NewDuration(time.Second), true)
+
+	assert.NoError(t, p.Close())
+	assert.Equal(t, http.MethodPut, method)
+	assert.Equal(t, 1, pushes)
+	assert.Equal(t, "/metrics/job/batch", path)
+	assert.Contains(t, string(body), "pushgateway_section_foo_bar_baz_seconds")
+
+	// second close is no-op
+	assert.NoError(t, p.Close())
+	assert.Equal(t, 1, pushes)
+}
+
+func TestPrometheusClient_PushGatewayError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	p := NewPrometheus("pushgateway", newMockIncrementerFactory(), newMockStateFactory(), WithPushGateway(server.URL, "batch", time.Hour))
+	assert.Error(t, p.Close())
+}
+
+func newRegistryPrometheus(namespace string, registry *prometheus.Registry) *Prometheus {
+	return NewPrometheus(
+		namespace,
+		incrementer.NewPrometheusIncrementerFactoryWithRegisterer(registry),
+		state.NewPrometheusStateFactoryWithRegisterer(registry),
+		WithRegistry(registry, registry),
+	)
+}
+
+func TestPrometheusClient_WithRegistry(t *testing.T) {
+	registry1 := prometheus.NewRegistry()
+	registry2 := prometheus.NewRegistry()
+
+	p1 := newRegistryPrometheus("namespace", registry1)
+	p2 := newRegistryPrometheus("namespace", registry2)
+
+	p1.TrackOperation("section", bucket.NewMetricOperation("foo"), timer.NewDuration(time.Second), true)
+	p2.TrackOperation("section", bucket.NewMetricOperation("foo"), timer.NewDuration(time.Second), true)
+	p2.TrackState("section", bucket.NewMetricOperation("bar"), 42)
+
+	families1, err := registry1.Gather()
+	require.NoError(t, err)
+	families2, err := registry2.Gather()
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, len(families1))
+	assert.Equal(t, 4, len(families2))
+
+	w := httptest.NewRecorder()
+	p1.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "namespace_section_foo_seconds")
+	assert.NotContains(t, w.Body.String(), "namespace_section_bar")
+}
+
+func TestPrometheusClient_HistogramRegistrationError(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	p1 := newRegistryPrometheus("namespace", registry)
+	p2 := newRegistryPrometheus("namespace", registry)
+
+	p1.TrackOperation("section", bucket.NewMetricOperation("foo"), timer.NewDuration(time.Second), true)
+	// the same histogram with different label names can not be registered, but must not panic
+	p2.TrackOperation("section", bucket.NewMetricOperation("foo").WithLabels(map[string]string{"country": "de"}), timer.NewDuration(time.Second), true)
+	assert.Equal(t, 0, len(p2.histograms))
+
+	// the same histogram with the same labels is reused
+	p2.TrackOperation("section", bucket.NewMetricOperation("foo"), timer.NewDuration(time.Second), true)
+	assert.True(t, p1.histograms["namespace_section_foo"] == p2.histograms["namespace_section_foo"])
+}
+
+func TestPrometheusClient_StableLabelSeries(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	p := newRegistryPrometheus("namespace", registry)
+
+	for n := 0; n < 50; n++ {
+		p.TrackOperation("section", bucket.NewMetricOperation("foo").WithLabels(map[string]string{"country": "de", "brand": "hf", "platform": "web"}), timer.NewDuration(time.Second), true)
+		p.TrackOperation("section", bucket.NewMetricOperation("foo").WithLabels(map[string]string{"country": "us", "brand": "ep", "platform": "ios"}), timer.NewDuration(time.Second), true)
+	}
+	// label set change for existing metric must not panic nor mix up existing series
+	p.TrackOperation("section", bucket.NewMetricOperation("foo").WithLabels(map[string]string{"country": "de"}), timer.NewDuration(time.Second), true)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.NotEmpty(t, families)
+	for _, family := range families {
+		require.Equal(t, 2, len(family.GetMetric()), family.GetName())
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, pair := range metric.GetLabel() {
+				labels[pair.GetName()] = pair.GetValue()
+			}
+
+			if labels["country"] == "de" {
+				assert.Equal(t, "hf", labels["brand"], family.GetName())
+				assert.Equal(t, "web", labels["platform"], family.GetName())
+			} else {
+				assert.Equal(t, "ep", labels["brand"], family.GetName())
+				assert.Equal(t, "ios", labels["platform"], family.GetName())
+			}
+		}
+	}
+}
+
+func TestPrometheusClient_MaxLabelCardinality(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	p := NewPrometheus(
+		"namespace",
+		incrementer.NewPrometheusIncrementerFactoryWithRegisterer(registry),
+		state.NewPrometheusStateFactoryWithRegisterer(registry),
+		WithRegistry(registry, registry),
+		WithMaxLabelCardinality(2),
+	)
+
+	for _, user := range []string{"u1", "u2", "u3", "u4", "u1", "u5", "u3"} {
+		p.TrackMetric("section", bucket.NewMetricOperation("foo").WithLabels(map[string]string{"user": user, "country": "de"}))
+		p.TrackState("section", bucket.NewMetricOperation("bar").WithLabels(map[string]string{"user": user}), 1)
+		p.TrackOperation("timed", bucket.NewMetricOperation("baz").WithLabels(map[string]string{"user": user}), timer.NewDuration(time.Second), true)
+	}
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	series := map[string]map[string]float64{}
+	for _, family := range families {
+		series[family.GetName()] = map[string]float64{}
+		for _, metric := range family.GetMetric() {
+			var user string
+			for _, pair := range metric.GetLabel() {
+				if pair.GetName() == "user" || pair.GetName() == "metric" {
+					user = pair.GetValue()
+				}
+			}
+			series[family.GetName()][user] = metric.GetCounter().GetValue() + metric.GetGauge().GetValue()
+		}
+	}
+
+	assert.Equal(t, map[string]float64{"u1": 2, "u2": 1, OverflowLabelValue: 4}, series["namespace_section_foo"])
+	assert.Equal(t, map[string]float64{"u1": 1, "u2": 1, OverflowLabelValue: 1}, series["namespace_section_bar"])
+	// every distinct rejected combination is counted once, repeated ones and histograms do not add up
+	assert.Equal(t, map[string]float64{
+		"namespace_section_foo":   3,
+		"namespace_total_section": 3,
+		"namespace_section_bar":   3,
+		"namespace_timed_baz":     3,
+		"namespace_total_timed":   3,
+	}, series["namespace_rejected_series_total"])
+}
+
+func TestPrometheusClient_MaxLabelCardinalityBoundedRejected(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	p := NewPrometheus(
+		"namespace",
+		incrementer.NewPrometheusIncrementerFactoryWithRegisterer(registry),
+		state.NewPrometheusStateFactoryWithRegisterer(registry),
+		WithRegistry(registry, registry),
+		WithMaxLabelCardinality(10),
+	)
+
+	const total = 100000
+	for i := 0; i < total; i++ {
+		p.TrackState("section", bucket.NewMetricOperation("foo").WithLabels(map[string]string{"user": strconv.Itoa(i)}), 1)
+	}
+
+	seen := p.labelSeries["namespace_section_foo"]
+	require.NotNil(t, seen)
+	assert.Len(t, seen.values, 10)
+	assert.Len(t, seen.rejected.registers, 1<<hyperLogLogPrecision)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	var rejected float64
+	for _, family := range families {
+		if family.GetName() == "namespace_rejected_series_total" {
+			rejected = family.GetMetric()[0].GetCounter().GetValue()
+		}
+	}
+	assert.InEpsilon(t, total-10, rejected, 0.05)
+}
+
+func TestPrometheusClient_LabelCardinalityLimitNoNamespace(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	p := NewPrometheus(
+		"",
+		incrementer.NewPrometheusIncrementerFactoryWithRegisterer(registry),
+		state.NewPrometheusStateFactoryWithRegisterer(registry),
+		WithRegistry(registry, registry),
+		WithMaxLabelCardinality(1),
+	)
+
+	p.TrackState("section", bucket.NewMetricOperation("foo").WithLabels(map[string]string{"user": "u1"}), 1)
+	p.TrackState("section", bucket.NewMetricOperation("foo").WithLabels(map[string]string{"user": "u2"}), 1)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	var names []string
+	for _, family := range families {
+		names = append(names, family.GetName())
+	}
+	assert.Contains(t, names, "rejected_series_total")
+}
+
+func TestPrometheusClient_NoLabelCardinalityLimit(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	p := newRegistryPrometheus("namespace", registry)
+
+	for i := 0; i < 100; i++ {
+		p.TrackMetric("section", bucket.NewMetricOperation("foo").WithLabels(map[string]string{"user": strconv.Itoa(i)}))
+	}
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		assert.NotEqual(t, "namespace_rejected_series_total", family.GetName())
+		assert.Equal(t, 100, len(family.GetMetric()))
+	}
+}
+
+func TestPrometheusClient_HistogramBuckets(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	p := NewPrometheus(
+		"namespace",
+		incrementer.NewPrometheusIncrementerFactoryWithRegisterer(registry),
+		state.NewPrometheusStateFactoryWithRegisterer(registry),
+		WithRegistry(registry, registry),
+		WithDefaultHistogramBuckets([]float64{0.5, 1}),
+		WithHistogramBuckets("db", []float64{0.001, 0.01}),
+	)
+
+	p.TrackOperation("http", bucket.NewMetricOperation("foo"), timer.NewDuration(700*time.Millisecond), true)
+	p.TrackOperation("db", bucket.NewMetricOperation("foo"), timer.NewDuration(5*time.Millisecond), true)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	histograms := make(map[string]*dto.Histogram)
+	for _, family := range families {
+		if family.GetType() == dto.MetricType_HISTOGRAM {
+			histograms[family.GetName()] = family.GetMetric()[0].GetHistogram()
+		}
+	}
+
+	httpHistogram := histograms["namespace_http_foo_seconds"]
+	require.NotNil(t, httpHistogram)
+	assert.InDelta(t, 0.7, httpHistogram.GetSampleSum(), 0.0001)
+	require.Equal(t, 2, len(httpHistogram.GetBucket()))
+	assert.Equal(t, 0.5, httpHistogram.GetBucket()[0].GetUpperBound())
+	assert.Equal(t, uint64(0), httpHistogram.GetBucket()[0].GetCumulativeCount())
+	assert.Equal(t, uint64(1), httpHistogram.GetBucket()[1].GetCumulativeCount())
+
+	dbHistogram := histograms["namespace_db_foo_seconds"]
+	require.NotNil(t, dbHistogram)
+	assert.InDelta(t, 0.005, dbHistogram.GetSampleSum(), 0.0001)
+	require.Equal(t, 2, len(dbHistogram.GetBucket()))
+	assert.Equal(t, 0.001, dbHistogram.GetBucket()[0].GetUpperBound())
+	assert.Equal(t, uint64(0), dbHistogram.GetBucket()[0].GetCumulativeCount())
+	assert.Equal(t, uint64(1), dbHistogram.GetBucket()[1].GetCumulativeCount())
+}
+
+func TestPrometheusClient_TrackRequest(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	p := newRegistryPrometheus("namespace", registry)
+
+	r := httptest.NewRequest(http.MethodGet, "/users/foo/bar", nil)
+	p.TrackRequest(r, timer.NewDuration(250*time.Millisecond), true)
+	p.TrackRequest(r, timer.NewDuration(750*time.Millisecond), true)
+	p.TrackRequest(r, nil, false)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	var histogram *dto.MetricFamily
+	for _, family := range families {
+		if family.GetName() == "namespace_request_duration_seconds" {
+			histogram = family
+		}
+	}
+	require.NotNil(t, histogram)
+	require.Equal(t, 1, len(histogram.GetMetric()))
+
+	metric := histogram.GetMetric()[0]
+	labels := make(map[string]string)
+	for _, pair := range metric.GetLabel() {
+		labels[pair.GetName()] = pair.GetValue()
+	}
+	assert.Equal(t, map[string]string{"method": "GET", "route": "/users/foo", "success": "true"}, labels)
+	assert.Equal(t, uint64(2), metric.GetHistogram().GetSampleCount())
+	assert.InDelta(t, 1, metric.GetHistogram().GetSampleSum(), 0.0001)
+}
+
+func TestPrometheusClient_TrackRequestRoutePattern(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	p := newRegistryPrometheus("namespace", registry)
+	p.SetHTTPMetricCallback(bucket.NewRoutePatternCallback(nil, func(r *http.Request) string {
+		return "/users/{id}"
+	}))
+
+	p.TrackRequest(httptest.NewRequest(http.MethodGet, "/users/123", nil), timer.NewDuration(time.Second), true)
+	p.TrackRequest(httptest.NewRequest(http.MethodGet, "/users/456", nil), timer.NewDuration(time.Second), true)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	names := make(map[string]int)
+	for _, family := range families {
+		names[family.GetName()] = len(family.GetMetric())
+		for _, metric := range family.GetMetric() {
+			for _, pair := range metric.GetLabel() {
+				if pair.GetName() == "route" {
+					assert.Equal(t, "/users/{id}", pair.GetValue())
+				}
+			}
+		}
+	}
+	assert.Equal(t, map[string]int{
+		"namespace_get_users_id":             1,
+		"namespace_total":                    1,
+		"namespace_request_duration_seconds": 1,
+	}, names)
+}
+
+func TestPrometheusClient_TrackSummary(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	p := NewPrometheus(
+		"namespace",
+		incrementer.NewPrometheusIncrementerFactoryWithRegisterer(registry),
+		state.NewPrometheusStateFactoryWithRegisterer(registry),
+		WithRegistry(registry, registry),
+		WithSummaryObjectives(map[float64]float64{0.5: 0.05}),
+	)
+
+	operation := bucket.NewMetricOperation("payload", "size").WithLabels(map[string]string{"topic": "orders"})
+	for _, value := range []float64{100, 200, 300} {
+		p.TrackSummary("kafka", operation, value)
+	}
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(families))
+	assert.Equal(t, "namespace_kafka_payload_size", families[0].GetName())
+	assert.Equal(t, dto.MetricType_SUMMARY, families[0].GetType())
+
+	summary := families[0].GetMetric()[0].GetSummary()
+	assert.Equal(t, uint64(3), summary.GetSampleCount())
+	assert.Equal(t, float64(600), summary.GetSampleSum())
+	require.Equal(t, 1, len(summary.GetQuantile()))
+	assert.Equal(t, 0.5, summary.GetQuantile()[0].GetQuantile())
+	assert.Equal(t, float64(200), summary.GetQuantile()[0].GetValue())
+}
+
+func TestPrometheusClient_TrackStateFloat(t *testing.T) {
+	m := newMockIncrementerFactory()
+	s := newMockStateFactory()
+	p := NewPrometheus("namespace", m, s)
+
+	p.TrackStateFloat("section", bucket.NewMetricOperation("memory"), 1.5)
+	p.TrackStateAdd("section", bucket.NewMetricOperation("memory"), 0.25)
+	p.TrackStateSub("section", bucket.NewMetricOperation("memory"), 0.5)
+
+	assert.Equal(t, 1, s.createMethodCalled)
+	assert.Equal(t, []float64{1.5}, s.s.setFloatMethodValues)
+	assert.Equal(t, []float64{0.25}, s.s.addMethodDeltas)
+	assert.Equal(t, []float64{0.5}, s.s.subMethodDeltas)
+}
+
+func TestPrometheusClient_TrackStateAdd(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	p := newRegistryPrometheus("namespace", registry)
+
+	operation := bucket.NewMetricOperation("requests", "inflight")
+	p.TrackStateAdd("http", operation, 3)
+	p.TrackStateSub("http", operation, 1)
+	p.TrackStateAdd("http", operation, 0.5)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(families))
+	assert.Equal(t, "namespace_http_requests_inflight", families[0].GetName())
+	assert.Equal(t, 2.5, families[0].GetMetric()[0].GetGauge().GetValue())
+}
+
+func TestPrometheusClient_TrackUnique(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	p := newRegistryPrometheus("namespace", registry)
+
+	de := bucket.NewMetricOperation("users").WithLabels(map[string]string{"country": "de"})
+	at := bucket.NewMetricOperation("users").WithLabels(map[string]string{"country": "at"})
+	for _, user := range []string{"u1", "u2", "u1", "u3"} {
+		p.TrackUnique("app", de, user)
+	}
+	p.TrackUnique("app", at, "u1")
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(families))
+	assert.Equal(t, "namespace_app_users", families[0].GetName())
+
+	values := make(map[string]float64)
+	for _, metric := range families[0].GetMetric() {
+		values[metric.GetLabel()[0].GetValue()] = metric.GetGauge().GetValue()
+	}
+	assert.Equal(t, map[string]float64{"de": 3, "at": 1}, values)
+}
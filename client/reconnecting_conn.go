@@ -0,0 +1,119 @@
+package client
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	reconnectMinBackoff = 100 * time.Millisecond
+	reconnectMaxBackoff = time.Minute
+)
+
+var (
+	// ErrReconnectBackoff is an error returned when metrics are not sent as connection is waiting for reconnect backoff
+	ErrReconnectBackoff = errors.New("connection is in reconnect backoff")
+	// ErrConnClosed is an error returned when metrics are sent after connection is closed
+	ErrConnClosed = errors.New("connection is closed")
+)
+
+// reconnectingConn is a connection wrapper that re-dials on write errors,
+// failed dials are retried not earlier than after exponentially growing backoff
+type reconnectingConn struct {
+	sync.Mutex
+
+	dial       func() (net.Conn, error)
+	conn       net.Conn
+	backoff    time.Duration
+	nextDialAt time.Time
+	now        func() time.Time
+	closed     bool
+}
+
+func newReconnectingConn(dial func() (net.Conn, error)) *reconnectingConn {
+	return &reconnectingConn{dial: dial, now: time.Now}
+}
+
+// Connect establishes connection if it is not established yet
+func (c *reconnectingConn) Connect() error {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.connect()
+}
+
+func (c *reconnectingConn) connect() error {
+	if c.closed {
+		return ErrConnClosed
+	}
+
+	if c.conn != nil {
+		return nil
+	}
+
+	if c.now().Before(c.nextDialAt) {
+		return ErrReconnectBackoff
+	}
+
+	conn, err := c.dial()
+	if err != nil {
+		switch {
+		case c.backoff == 0:
+			c.backoff = reconnectMinBackoff
+		case c.backoff < reconnectMaxBackoff:
+			c.backoff *= 2
+			if c.backoff > reconnectMaxBackoff {
+				c.backoff = reconnectMaxBackoff
+			}
+		}
+		c.nextDialAt = c.now().Add(c.backoff)
+
+		return err
+	}
+
+	c.conn = conn
+	c.backoff = 0
+	c.nextDialAt = time.Time{}
+
+	return nil
+}
+
+// Send writes data to the connection, connection is re-established once if write fails
+func (c *reconnectingConn) Send(b []byte) error {
+	c.Lock()
+	defer c.Unlock()
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if err = c.connect(); err != nil {
+			return err
+		}
+
+		if _, err = c.conn.Write(b); err == nil {
+			return nil
+		}
+
+		c.conn.Close()
+		c.conn = nil
+	}
+
+	return err
+}
+
+// Close closes underlying connection if any, connection is not re-established after it is closed
+func (c *reconnectingConn) Close() error {
+	c.Lock()
+	defer c.Unlock()
+
+	c.closed = true
+	if c.conn == nil {
+		return nil
+	}
+
+	err := c.conn.Close()
+	c.conn = nil
+
+	return err
+}
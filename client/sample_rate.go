@@ -0,0 +1,93 @@
+package client
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/hellofresh/stats-go/bucket"
+)
+
+// ErrInvalidSampleRate is an error returned when sample rate is not a number in (0, 1] range
+var ErrInvalidSampleRate = errors.New("invalid sample rate")
+
+// ParseSampleRate parses sample rate string, e.g. "0.1", rate must be in (0, 1] range
+func ParseSampleRate(value string) (float32, error) {
+	rate, err := strconv.ParseFloat(value, 32)
+	if err != nil || rate <= 0 || rate > 1 {
+		return 0, ErrInvalidSampleRate
+	}
+
+	return float32(rate), nil
+}
+
+// StatsDOption is a function that configures StatsD and DogStatsD clients
+type StatsDOption func(*statsdOptions)
+
+// statsdOptions holds StatsD and DogStatsD clients configuration
+type statsdOptions struct {
+	network       string
+	maxPacketSize int
+	sampleRates   sampleRates
+	flushInterval time.Duration
+	percentiles   []float64
+}
+
+// WithSampleRate sets client-side sample rate for all sections w/out section specific rate, e.g. 0.1 to send
+// only 10% of counters and timings, statsd server scales received values with the rate sent as "|@0.1"
+func WithSampleRate(rate float32) StatsDOption {
+	return func(o *statsdOptions) {
+		o.sampleRates.defaultRate = rate
+	}
+}
+
+// WithSectionSampleRate sets client-side sample rate for the given section, including HTTP Request section
+func WithSectionSampleRate(section string, rate float32) StatsDOption {
+	return func(o *statsdOptions) {
+		if o.sampleRates.sections == nil {
+			o.sampleRates.sections = make(map[string]float32)
+		}
+		o.sampleRates.sections[section] = rate
+	}
+}
+
+func buildStatsDOptions(opts []StatsDOption) *statsdOptions {
+	o := &statsdOptions{percentiles: DefaultStatsDPercentiles}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// sampleRates holds client-side sample rates by section
+type sampleRates struct {
+	defaultRate float32
+	sections    map[string]float32
+}
+
+// rate returns sample rate for metric operation in the section, operation sample rate has precedence over
+// section rate and section rate has precedence over default one, rates out of (0, 1) range mean no sampling
+func (r sampleRates) rate(section string, operation *bucket.MetricOperation) float32 {
+	rate := r.defaultRate
+	if sectionRate, ok := r.sections[section]; ok {
+		rate = sectionRate
+	}
+	if operation != nil && operation.SampleRate > 0 {
+		rate = operation.SampleRate
+	}
+
+	if rate <= 0 || rate > 1 {
+		return 1
+	}
+	return rate
+}
+
+// sampledClient returns statsd client copy that sends metrics with the given sample rate
+func sampledClient(client statsdClient, rate float32) statsdClient {
+	if rate >= 1 {
+		return client
+	}
+
+	return client.WithSampleRate(rate)
+}
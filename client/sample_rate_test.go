@@ -0,0 +1,34 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/hellofresh/stats-go/bucket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSampleRate(t *testing.T) {
+	rate, err := ParseSampleRate("0.25")
+	assert.NoError(t, err)
+	assert.Equal(t, float32(0.25), rate)
+
+	rate, err = ParseSampleRate("1")
+	assert.NoError(t, err)
+	assert.Equal(t, float32(1), rate)
+
+	for _, value := range []string{"", "foo", "0", "-0.5", "1.5"} {
+		_, err = ParseSampleRate(value)
+		assert.Equal(t, ErrInvalidSampleRate, err, value)
+	}
+}
+
+func TestSampleRates_Rate(t *testing.T) {
+	rates := buildStatsDOptions([]StatsDOption{WithSampleRate(0.5), WithSectionSampleRate("orders", 0.1)}).sampleRates
+
+	assert.Equal(t, float32(0.5), rates.rate("section", nil))
+	assert.Equal(t, float32(0.1), rates.rate("orders", bucket.NewMetricOperation("foo")))
+	assert.Equal(t, float32(0.01), rates.rate("orders", bucket.NewMetricOperation("foo").WithSampleRate(0.01)))
+
+	assert.Equal(t, float32(1), sampleRates{}.rate("section", nil))
+	assert.Equal(t, float32(1), sampleRates{defaultRate: 5}.rate("section", nil))
+}
@@ -0,0 +1,193 @@
+package client
+
+import (
+	"net/http"
+
+	"github.com/hellofresh/stats-go/bucket"
+	"github.com/hellofresh/stats-go/timer"
+)
+
+// Scoped is Client implementation returned by Client.With and Client.WithSection, it tracks metrics with parent
+// client adding preset labels to every metric operation and prefixing every section, e.g. "orders" prefix turns
+// "db" section into "orders_db" one. Operation labels take precedence over preset ones with the same key.
+// HTTP Request metrics, callback and section are shared with parent client as they are not operation based.
+type Scoped struct {
+	parent        Client
+	labels        map[string]string
+	sectionPrefix string
+}
+
+// newScoped builds Scoped client with preset labels copy, so that caller changes do not affect it
+func newScoped(parent Client, labels map[string]string, sectionPrefix string) *Scoped {
+	scoped := &Scoped{parent: parent, sectionPrefix: sectionPrefix}
+	if len(labels) > 0 {
+		scoped.labels = make(map[string]string, len(labels))
+		for k, v := range labels {
+			scoped.labels[k] = v
+		}
+	}
+
+	return scoped
+}
+
+// Parent returns client metrics are tracked with
+func (c *Scoped) Parent() Client {
+	return c.parent
+}
+
+// section returns section with preset prefix, prefix and section are joined with dot that becomes underscore
+// in sanitized metric name
+func (c *Scoped) section(section string) string {
+	if c.sectionPrefix == "" {
+		return section
+	}
+
+	return c.sectionPrefix + "." + section
+}
+
+// operation returns operation copy with preset labels added
+func (c *Scoped) operation(operation *bucket.MetricOperation) *bucket.MetricOperation {
+	if len(c.labels) == 0 {
+		return operation
+	}
+
+	scoped := operation.Clone()
+	if scoped.Labels == nil {
+		scoped.Labels = make(map[string]string, len(c.labels))
+	}
+	for k, v := range c.labels {
+		if _, ok := scoped.Labels[k]; !ok {
+			scoped.Labels[k] = v
+		}
+	}
+
+	return scoped
+}
+
+// With returns child client that adds given labels to preset ones
+func (c *Scoped) With(labels map[string]string) Client {
+	scoped := newScoped(c.parent, c.labels, c.sectionPrefix)
+	if scoped.labels == nil {
+		scoped.labels = make(map[string]string, len(labels))
+	}
+	for k, v := range labels {
+		scoped.labels[k] = v
+	}
+
+	return scoped
+}
+
+// WithSection returns child client that prefixes every section with preset and given prefixes
+func (c *Scoped) WithSection(prefix string) Client {
+	return newScoped(c.parent, c.labels, c.section(prefix))
+}
+
+// BuildTimer builds timer to track metric timings
+func (c *Scoped) BuildTimer() timer.Timer {
+	return c.parent.BuildTimer()
+}
+
+// Close does nothing as child client does not own parent client connection, parent client must be closed instead
+func (c *Scoped) Close() error {
+	return nil
+}
+
+// TrackRequest tracks HTTP Request stats
+func (c *Scoped) TrackRequest(r *http.Request, t timer.Timer, success bool) Client {
+	c.parent.TrackRequest(r, t, success)
+	return c
+}
+
+// TrackOperation tracks custom operation
+func (c *Scoped) TrackOperation(section string, operation *bucket.MetricOperation, t timer.Timer, success bool) Client {
+	c.parent.TrackOperation(c.section(section), c.operation(operation), t, success)
+	return c
+}
+
+// TrackOperationN tracks custom operation with n diff
+func (c *Scoped) TrackOperationN(section string, operation *bucket.MetricOperation, t timer.Timer, n int, success bool) Client {
+	c.parent.TrackOperationN(c.section(section), c.operation(operation), t, n, success)
+	return c
+}
+
+// TrackMetric tracks custom metric, w/out ok/fail additional sections
+func (c *Scoped) TrackMetric(section string, operation *bucket.MetricOperation) Client {
+	c.parent.TrackMetric(c.section(section), c.operation(operation))
+	return c
+}
+
+// TrackMetricN tracks custom metric with n diff, w/out ok/fail additional sections
+func (c *Scoped) TrackMetricN(section string, operation *bucket.MetricOperation, n int) Client {
+	c.parent.TrackMetricN(c.section(section), c.operation(operation), n)
+	return c
+}
+
+// TrackState tracks metric absolute value
+func (c *Scoped) TrackState(section string, operation *bucket.MetricOperation, value int) Client {
+	c.parent.TrackState(c.section(section), c.operation(operation), value)
+	return c
+}
+
+// TrackStateFloat tracks metric absolute float value
+func (c *Scoped) TrackStateFloat(section string, operation *bucket.MetricOperation, value float64) Client {
+	c.parent.TrackStateFloat(c.section(section), c.operation(operation), value)
+	return c
+}
+
+// TrackStateAdd increases metric state by delta
+func (c *Scoped) TrackStateAdd(section string, operation *bucket.MetricOperation, delta float64) Client {
+	c.parent.TrackStateAdd(c.section(section), c.operation(operation), delta)
+	return c
+}
+
+// TrackStateSub decreases metric state by delta
+func (c *Scoped) TrackStateSub(section string, operation *bucket.MetricOperation, delta float64) Client {
+	c.parent.TrackStateSub(c.section(section), c.operation(operation), delta)
+	return c
+}
+
+// TrackSummary tracks metric value distribution
+func (c *Scoped) TrackSummary(section string, operation *bucket.MetricOperation, value float64) Client {
+	c.parent.TrackSummary(c.section(section), c.operation(operation), value)
+	return c
+}
+
+// TrackUnique tracks number of unique values
+func (c *Scoped) TrackUnique(section string, operation *bucket.MetricOperation, value string) Client {
+	c.parent.TrackUnique(c.section(section), c.operation(operation), value)
+	return c
+}
+
+// SetHTTPMetricCallback sets parent client callback handler that allows metric operation alteration for HTTP Request
+func (c *Scoped) SetHTTPMetricCallback(callback bucket.HTTPMetricNameAlterCallback) Client {
+	c.parent.SetHTTPMetricCallback(callback)
+	return c
+}
+
+// GetHTTPMetricCallback gets parent client callback handler that allows metric operation alteration for HTTP Request
+func (c *Scoped) GetHTTPMetricCallback() bucket.HTTPMetricNameAlterCallback {
+	return c.parent.GetHTTPMetricCallback()
+}
+
+// SetHTTPRequestSection sets parent client metric section for HTTP Request metrics
+func (c *Scoped) SetHTTPRequestSection(section string) Client {
+	c.parent.SetHTTPRequestSection(section)
+	return c
+}
+
+// ResetHTTPRequestSection resets parent client metric section for HTTP Request metrics to default value
+func (c *Scoped) ResetHTTPRequestSection() Client {
+	c.parent.ResetHTTPRequestSection()
+	return c
+}
+
+// SetOperationDepth sets parent client number of operations in metric names
+func (c *Scoped) SetOperationDepth(depth int) Client {
+	c.parent.SetOperationDepth(depth)
+	return c
+}
+
+// Handler returns metrics endpoint for prometheus backend
+func (c *Scoped) Handler() http.Handler {
+	return c.parent.Handler()
+}
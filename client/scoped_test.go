@@ -0,0 +1,63 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/hellofresh/stats-go/bucket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScoped_WithSection(t *testing.T) {
+	memory := NewMemory(false)
+
+	client := memory.WithSection("orders")
+	client.TrackMetric("db", bucket.NewMetricOperation("foo"))
+	client.WithSection("eu").TrackState("db", bucket.NewMetricOperation("bar"), 5)
+
+	assert.Equal(t, 1, memory.CountMetrics["orders_db.foo.-.-"])
+	assert.Equal(t, 1, memory.CountMetrics["total.orders_db"])
+	assert.Equal(t, 5, memory.StateMetrics["orders_eu_db.bar.-.-"])
+
+	// child client does not close parent one
+	require.NoError(t, client.Close())
+	assert.Len(t, memory.CountMetrics, 2)
+}
+
+func TestScoped_With(t *testing.T) {
+	recording := &recordingClient{Noop: NewNoop()}
+
+	client := newScoped(recording, map[string]string{"service": "checkout", "country": "de"}, "").With(map[string]string{"env": "prod"})
+
+	operation := bucket.NewMetricOperation("foo").WithLabels(map[string]string{"country": "at"})
+	client.TrackMetric("section", operation)
+	client.TrackMetric("section", bucket.NewMetricOperation("bar"))
+
+	assert.Equal(t, []map[string]string{
+		{"service": "checkout", "country": "at", "env": "prod"},
+		{"service": "checkout", "country": "de", "env": "prod"},
+	}, recording.labels)
+	assert.Equal(t, map[string]string{"country": "at"}, operation.Labels)
+
+	scoped, ok := client.(*Scoped)
+	require.True(t, ok)
+	assert.Equal(t, recording, scoped.Parent())
+}
+
+func TestScoped_WithLabelsCopy(t *testing.T) {
+	recording := &recordingClient{Noop: NewNoop()}
+
+	labels := map[string]string{"service": "checkout"}
+	client := NewMemory(false).With(labels)
+	scoped := newScoped(recording, labels, "")
+	child := scoped.With(nil)
+
+	// caller changes after the call do not affect preset labels
+	labels["service"] = "payment"
+	labels["country"] = "de"
+
+	scoped.TrackMetric("section", bucket.NewMetricOperation("foo"))
+	child.TrackMetric("section", bucket.NewMetricOperation("foo"))
+	assert.Equal(t, []map[string]string{{"service": "checkout"}, {"service": "checkout"}}, recording.labels)
+	assert.Equal(t, map[string]string{"service": "checkout"}, client.(*Scoped).labels)
+}
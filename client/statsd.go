@@ -10,47 +10,53 @@ import (
 	"github.com/hellofresh/stats-go/log"
 	"github.com/hellofresh/stats-go/state"
 	"github.com/hellofresh/stats-go/timer"
-	"gopkg.in/alexcesaro/statsd.v2"
 )
 
-// StatsD is Client implementation for statsd
+// StatsD is Client implementation for statsd. Client-side sample rates, see WithSampleRate, are applied
+// to counters, timings and histograms only, gauges and sets are always sent.
+// In aggregation mode, see WithAggregation, counters, gauges and timings are aggregated in memory and sent periodically.
 type StatsD struct {
 	sync.Mutex
-	client             *statsd.Client
+	client             statsdClient
+	aggregator         *statsdAggregator
+	sampleRates        sampleRates
 	httpMetricCallback bucket.HTTPMetricNameAlterCallback
 	httpRequestSection string
+	operationDepth     int
 	unicode            bool
 }
 
 // NewStatsD builds and returns new StatsD instance
-func NewStatsD(addr string, prefix string, unicode bool) (*StatsD, error) {
-	var options []statsd.Option
-
-	if prefix != "" {
-		options = append(options, statsd.Prefix(prefix))
-	}
-
-	if addr != "" {
-		options = append(options, statsd.Address(addr))
-	}
+func NewStatsD(addr string, prefix string, unicode bool, opts ...StatsDOption) (*StatsD, error) {
+	o := buildStatsDOptions(opts)
 
 	log.Log("Trying to connect to statsd instance", map[string]interface{}{
-		"addr":   addr,
-		"prefix": prefix,
+		"addr":    addr,
+		"prefix":  prefix,
+		"network": o.network,
 	}, nil)
 
-	statsdClient, err := statsd.New(options...)
+	statsdClient, err := newStatsDClient(addr, prefix, false, o)
 	if err != nil {
 		log.Log("An error occurred while connecting to StatsD", map[string]interface{}{
-			"addr":   addr,
-			"prefix": prefix,
+			"addr":    addr,
+			"prefix":  prefix,
+			"network": o.network,
 		}, err)
 		return nil, err
 	}
 
-	client := &StatsD{client: statsdClient, unicode: unicode}
+	client := &StatsD{
+		client:      statsdClient,
+		sampleRates: o.sampleRates,
+		unicode:     unicode,
+	}
 	client.ResetHTTPRequestSection()
 
+	if o.flushInterval > 0 {
+		client.aggregator = newStatsDAggregator(statsdClient, o.flushInterval, o.percentiles)
+	}
+
 	return client, nil
 }
 
@@ -59,19 +65,39 @@ func (c *StatsD) BuildTimer() timer.Timer {
 	return &timer.Memory{}
 }
 
-// Close statsd connection
+// Flush sends metrics aggregated since the previous flush in aggregation mode and buffered metrics otherwise
+func (c *StatsD) Flush() {
+	if c.aggregator != nil {
+		c.aggregator.Flush()
+		return
+	}
+
+	c.client.Flush()
+}
+
+// Close sends aggregated metrics if any and closes statsd connection
 func (c *StatsD) Close() error {
-	c.client.Close()
-	return nil
+	if c.aggregator != nil {
+		c.aggregator.Close()
+	}
+
+	return c.client.Close()
 }
 
 // TrackRequest tracks HTTP Request stats
 func (c *StatsD) TrackRequest(r *http.Request, t timer.Timer, success bool) Client {
-	b := bucket.NewHTTPRequest(c.httpRequestSection, r, success, c.httpMetricCallback, c.unicode)
-	i := incrementer.NewStatsD(c.client)
+	b := bucket.NewHTTPRequestWithDepth(c.httpRequestSection, r, success, c.httpMetricCallback, c.unicode, c.operationDepth)
+	if c.aggregator != nil {
+		c.aggregator.timing(b.Metric(), t)
+		c.aggregator.increment(1, b.Metric(), b.MetricWithSuffix(), b.MetricTotal(), b.MetricTotalWithSuffix())
+		return c
+	}
+
+	client := sampledClient(c.client, c.sampleRates.rate(c.httpRequestSection, nil))
+	i := incrementer.NewStatsD(client)
 
 	if nil != t {
-		c.client.Timing(b.Metric(), int(t.Finish()/time.Millisecond))
+		client.Timing(b.Metric(), int(t.Finish()/time.Millisecond))
 	}
 	i.IncrementAll(b)
 
@@ -80,11 +106,16 @@ func (c *StatsD) TrackRequest(r *http.Request, t timer.Timer, success bool) Clie
 
 // TrackOperation tracks custom operation
 func (c *StatsD) TrackOperation(section string, operation *bucket.MetricOperation, t timer.Timer, success bool) Client {
-	b := bucket.NewPlain(section, operation, success, c.unicode)
-	i := incrementer.NewStatsD(c.client)
+	if c.aggregator != nil {
+		return c.TrackOperationN(section, operation, t, 1, success)
+	}
+
+	b := bucket.NewPlainWithDepth(section, operation, success, c.unicode, c.operationDepth)
+	client := sampledClient(c.client, c.sampleRates.rate(section, operation))
+	i := incrementer.NewStatsD(client)
 
 	if nil != t {
-		c.client.Timing(b.MetricWithSuffix(), int(t.Finish()/time.Millisecond))
+		client.Timing(b.MetricWithSuffix(), int(t.Finish()/time.Millisecond))
 	}
 	i.IncrementAll(b)
 
@@ -93,11 +124,18 @@ func (c *StatsD) TrackOperation(section string, operation *bucket.MetricOperatio
 
 // TrackOperationN tracks custom operation with n diff
 func (c *StatsD) TrackOperationN(section string, operation *bucket.MetricOperation, t timer.Timer, n int, success bool) Client {
-	b := bucket.NewPlain(section, operation, success, c.unicode)
-	i := incrementer.NewStatsD(c.client)
+	b := bucket.NewPlainWithDepth(section, operation, success, c.unicode, c.operationDepth)
+	if c.aggregator != nil {
+		c.aggregator.timing(b.MetricWithSuffix(), t)
+		c.aggregator.increment(n, b.Metric(), b.MetricWithSuffix(), b.MetricTotal(), b.MetricTotalWithSuffix())
+		return c
+	}
+
+	client := sampledClient(c.client, c.sampleRates.rate(section, operation))
+	i := incrementer.NewStatsD(client)
 
 	if nil != t {
-		c.client.Timing(b.MetricWithSuffix(), int(t.Finish()/time.Millisecond))
+		client.Timing(b.MetricWithSuffix(), int(t.Finish()/time.Millisecond))
 	}
 	i.IncrementAllN(b, n)
 
@@ -106,8 +144,12 @@ func (c *StatsD) TrackOperationN(section string, operation *bucket.MetricOperati
 
 // TrackMetric tracks custom metric, w/out ok/fail additional sections
 func (c *StatsD) TrackMetric(section string, operation *bucket.MetricOperation) Client {
-	b := bucket.NewPlain(section, operation, true, c.unicode)
-	i := incrementer.NewStatsD(c.client)
+	if c.aggregator != nil {
+		return c.TrackMetricN(section, operation, 1)
+	}
+
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
+	i := incrementer.NewStatsD(sampledClient(c.client, c.sampleRates.rate(section, operation)))
 
 	i.Increment(b.Metric())
 	i.Increment(b.MetricTotal())
@@ -117,8 +159,13 @@ func (c *StatsD) TrackMetric(section string, operation *bucket.MetricOperation)
 
 // TrackMetricN tracks custom metric with n diff, w/out ok/fail additional sections
 func (c *StatsD) TrackMetricN(section string, operation *bucket.MetricOperation, n int) Client {
-	b := bucket.NewPlain(section, operation, true, c.unicode)
-	i := incrementer.NewStatsD(c.client)
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
+	if c.aggregator != nil {
+		c.aggregator.increment(n, b.Metric(), b.MetricTotal())
+		return c
+	}
+
+	i := incrementer.NewStatsD(sampledClient(c.client, c.sampleRates.rate(section, operation)))
 
 	i.IncrementN(b.Metric(), n)
 	i.IncrementN(b.MetricTotal(), n)
@@ -128,7 +175,11 @@ func (c *StatsD) TrackMetricN(section string, operation *bucket.MetricOperation,
 
 // TrackState tracks metric absolute value
 func (c *StatsD) TrackState(section string, operation *bucket.MetricOperation, value int) Client {
-	b := bucket.NewPlain(section, operation, true, c.unicode)
+	if c.aggregator != nil {
+		return c.TrackStateFloat(section, operation, float64(value))
+	}
+
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
 	s := state.NewStatsD(c.client)
 
 	s.Set(b.Metric(), value)
@@ -136,6 +187,69 @@ func (c *StatsD) TrackState(section string, operation *bucket.MetricOperation, v
 	return c
 }
 
+// TrackStateFloat tracks metric absolute float value
+func (c *StatsD) TrackStateFloat(section string, operation *bucket.MetricOperation, value float64) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
+	if c.aggregator != nil {
+		c.aggregator.setState(b.Metric(), value)
+		return c
+	}
+
+	s := state.NewStatsD(c.client)
+
+	s.SetFloat(b.Metric(), value)
+
+	return c
+}
+
+// TrackStateAdd increases metric state by delta
+func (c *StatsD) TrackStateAdd(section string, operation *bucket.MetricOperation, delta float64) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
+	if c.aggregator != nil {
+		c.aggregator.addState(b.Metric(), delta)
+		return c
+	}
+
+	s := state.NewStatsDWithGaugeWriter(c.client, c.client)
+
+	s.Add(b.Metric(), delta)
+
+	return c
+}
+
+// TrackStateSub decreases metric state by delta
+func (c *StatsD) TrackStateSub(section string, operation *bucket.MetricOperation, delta float64) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
+	if c.aggregator != nil {
+		c.aggregator.addState(b.Metric(), -delta)
+		return c
+	}
+
+	s := state.NewStatsDWithGaugeWriter(c.client, c.client)
+
+	s.Sub(b.Metric(), delta)
+
+	return c
+}
+
+// TrackSummary tracks metric value distribution as statsd histogram
+func (c *StatsD) TrackSummary(section string, operation *bucket.MetricOperation, value float64) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
+
+	sampledClient(c.client, c.sampleRates.rate(section, operation)).Histogram(b.Metric(), value)
+
+	return c
+}
+
+// TrackUnique tracks number of unique values per flush interval as statsd set
+func (c *StatsD) TrackUnique(section string, operation *bucket.MetricOperation, value string) Client {
+	b := bucket.NewPlainWithDepth(section, operation, true, c.unicode, c.operationDepth)
+
+	c.client.Unique(b.Metric(), value)
+
+	return c
+}
+
 // SetHTTPMetricCallback sets callback handler that allows metric operation alteration for HTTP Request
 func (c *StatsD) SetHTTPMetricCallback(callback bucket.HTTPMetricNameAlterCallback) Client {
 	c.Lock()
@@ -167,6 +281,26 @@ func (c *StatsD) ResetHTTPRequestSection() Client {
 	return c.SetHTTPRequestSection(bucket.SectionRequest)
 }
 
+// SetOperationDepth sets number of operations in metric names, shorter operations are padded with placeholders
+// and longer ones are truncated, zero value means bucket.MetricOperationsMaxLength
+func (c *StatsD) SetOperationDepth(depth int) Client {
+	c.Lock()
+	defer c.Unlock()
+
+	c.operationDepth = depth
+	return c
+}
+
+// With returns child client that adds given labels to every tracked metric operation
+func (c *StatsD) With(labels map[string]string) Client {
+	return newScoped(c, labels, "")
+}
+
+// WithSection returns child client that prefixes every tracked metric section with the given prefix
+func (c *StatsD) WithSection(prefix string) Client {
+	return newScoped(c, nil, prefix)
+}
+
 // Handler returns metrics endpoint for prometheus backend
 func (c *StatsD) Handler() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
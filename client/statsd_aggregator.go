@@ -0,0 +1,209 @@
+package client
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hellofresh/stats-go/state"
+	"github.com/hellofresh/stats-go/timer"
+)
+
+// DefaultStatsDFlushInterval is a default interval StatsD client sends aggregated metrics with in aggregation mode
+const DefaultStatsDFlushInterval = 10 * time.Second
+
+// DefaultStatsDPercentiles is a default list of timer percentiles StatsD client computes in aggregation mode
+var DefaultStatsDPercentiles = []float64{50, 90, 99}
+
+// WithAggregation enables StatsD client aggregation mode: counters are summed, gauges keep the last value
+// and timers are aggregated in memory and sent once per flush interval and on close, see statsdAggregator
+// for details. Sample rates are not applied to aggregated metrics as they are counted exactly.
+func WithAggregation(flushInterval time.Duration) StatsDOption {
+	return func(o *statsdOptions) {
+		if flushInterval <= 0 {
+			flushInterval = DefaultStatsDFlushInterval
+		}
+		o.flushInterval = flushInterval
+	}
+}
+
+// WithAggregationPercentiles sets timer percentiles, e.g. 50, 90, 99.9, StatsD client computes in aggregation mode
+func WithAggregationPercentiles(percentiles ...float64) StatsDOption {
+	return func(o *statsdOptions) {
+		o.percentiles = percentiles
+	}
+}
+
+// statsdGauge is an aggregated gauge value, relative gauge is a sum of deltas
+// that is sent as relative update as the absolute value is not known
+type statsdGauge struct {
+	value    float64
+	absolute bool
+}
+
+// statsdAggregator aggregates StatsD client metrics in memory and sends them periodically,
+// so that every metric is sent once per flush interval instead of every tracking call:
+// counters are sent as the sum of increments, gauges as the last value (or the sum of deltas if there was
+// no absolute value set), timers as "<metric>.count" counter and "<metric>.mean", "<metric>.lower",
+// "<metric>.upper" and "<metric>.p<percentile>", e.g. "<metric>.p99_9", gauges in milliseconds.
+type statsdAggregator struct {
+	sync.Mutex
+
+	client      statsdClient
+	percentiles []float64
+	done        chan struct{}
+	wg          sync.WaitGroup
+	closed      bool
+
+	counters map[string]int
+	gauges   map[string]*statsdGauge
+	timers   map[string][]float64
+}
+
+func newStatsDAggregator(client statsdClient, flushInterval time.Duration, percentiles []float64) *statsdAggregator {
+	a := &statsdAggregator{
+		client:      client,
+		percentiles: percentiles,
+		done:        make(chan struct{}),
+	}
+	a.resetMetrics()
+
+	a.wg.Add(1)
+	go a.flushPeriodically(flushInterval)
+
+	return a
+}
+
+func (a *statsdAggregator) resetMetrics() {
+	a.counters = map[string]int{}
+	a.gauges = map[string]*statsdGauge{}
+	a.timers = map[string][]float64{}
+}
+
+func (a *statsdAggregator) flushPeriodically(flushInterval time.Duration) {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			a.Flush()
+		case <-a.done:
+			return
+		}
+	}
+}
+
+// Flush sends all metrics aggregated since the previous flush
+func (a *statsdAggregator) Flush() {
+	a.Lock()
+	counters, gauges, timers := a.counters, a.gauges, a.timers
+	a.resetMetrics()
+	a.Unlock()
+
+	for metric, value := range counters {
+		a.client.Count(metric, value)
+	}
+
+	for metric, values := range timers {
+		sort.Float64s(values)
+
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+
+		a.client.Count(metric+".count", len(values))
+		a.client.Gauge(metric+".mean", sum/float64(len(values)))
+		a.client.Gauge(metric+".lower", values[0])
+		a.client.Gauge(metric+".upper", values[len(values)-1])
+		for _, p := range a.percentiles {
+			a.client.Gauge(metric+".p"+strings.Replace(formatFloat(p), ".", "_", -1), nearestRank(values, p/100))
+		}
+	}
+
+	absolute := state.NewStatsD(a.client)
+	relative := state.NewStatsDWithGaugeWriter(a.client, a.client)
+	for metric, gauge := range gauges {
+		if gauge.absolute {
+			absolute.SetFloat(metric, gauge.value)
+		} else {
+			relative.Add(metric, gauge.value)
+		}
+	}
+
+	a.client.Flush()
+}
+
+// Close stops periodical flushing and sends aggregated metrics
+func (a *statsdAggregator) Close() {
+	a.Lock()
+	if a.closed {
+		a.Unlock()
+		return
+	}
+	a.closed = true
+	a.Unlock()
+
+	close(a.done)
+	a.wg.Wait()
+
+	a.Flush()
+}
+
+func (a *statsdAggregator) increment(n int, metrics ...string) {
+	a.Lock()
+	defer a.Unlock()
+
+	for _, metric := range metrics {
+		a.counters[metric] += n
+	}
+}
+
+func (a *statsdAggregator) timing(metric string, t timer.Timer) {
+	if nil == t {
+		return
+	}
+
+	elapsed := float64(t.Finish()) / float64(time.Millisecond)
+
+	a.Lock()
+	defer a.Unlock()
+
+	a.timers[metric] = append(a.timers[metric], elapsed)
+}
+
+func (a *statsdAggregator) setState(metric string, value float64) {
+	a.Lock()
+	defer a.Unlock()
+
+	a.gauges[metric] = &statsdGauge{value: value, absolute: true}
+}
+
+func (a *statsdAggregator) addState(metric string, delta float64) {
+	a.Lock()
+	defer a.Unlock()
+
+	if gauge, ok := a.gauges[metric]; ok {
+		gauge.value += delta
+		return
+	}
+	a.gauges[metric] = &statsdGauge{value: delta}
+}
+
+// nearestRank returns q-quantile, 0 <= q <= 1, of sorted values using nearest-rank method
+func nearestRank(sorted []float64, q float64) float64 {
+	rank := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+
+	return sorted[rank]
+}
@@ -0,0 +1,282 @@
+package client
+
+import (
+	"errors"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hellofresh/stats-go/log"
+	"gopkg.in/alexcesaro/statsd.v2"
+)
+
+const (
+	// defaultStatsDAddr is an address statsd client connects to if address is not set
+	defaultStatsDAddr = ":8125"
+	// statsdDialTimeout is a timeout for establishing statsd connection
+	statsdDialTimeout = 5 * time.Second
+)
+
+// ErrUnknownStatsDNetwork is an error returned when trying to create statsd client for unsupported network
+var ErrUnknownStatsDNetwork = errors.New("unknown statsd network, must be one of udp, tcp, unix or unixgram")
+
+// WithNetwork sets network StatsD and DogStatsD clients send metrics over: "udp" (default), "tcp", "unix"
+// or "unixgram", for "unix" and "unixgram" networks address is a path to unix domain socket. Connections other than
+// UDP are re-established with exponential backoff if connection is lost and metrics are sent in batches
+// not bigger than max packet size: newline-separated over stream connections and every batch as a single datagram
+// for "unixgram" network, the one DogStatsD agent listens on.
+func WithNetwork(network string) StatsDOption {
+	return func(o *statsdOptions) {
+		o.network = network
+	}
+}
+
+// WithMaxPacketSize sets max size of metrics batch StatsD and DogStatsD clients send at once, default value is 1440
+func WithMaxPacketSize(size int) StatsDOption {
+	return func(o *statsdOptions) {
+		o.maxPacketSize = size
+	}
+}
+
+// statsdClient is statsd protocol client StatsD and DogStatsD clients send metrics with,
+// it also implements state.GaugeWriter to send relative gauge updates
+type statsdClient interface {
+	Increment(bucket string)
+	Count(bucket string, n interface{})
+	Gauge(bucket string, value interface{})
+	Timing(bucket string, value interface{})
+	Histogram(bucket string, value interface{})
+	Unique(bucket string, value string)
+
+	// WriteGauge sends gauge value as is, e.g. "+5", after all the metrics sent before
+	WriteGauge(bucket string, value string)
+	// WithSampleRate returns client copy that sends counters, timings and histograms with the given sample rate
+	WithSampleRate(rate float32) statsdClient
+	// WithTags returns client copy that sends given key-value pairs as DogStatsD tags with every metric
+	WithTags(tags ...string) statsdClient
+
+	// Flush sends buffered metrics
+	Flush()
+	// Close sends buffered metrics and closes connection
+	Close() error
+}
+
+// newStatsDClient creates statsd protocol client for the network set in options
+func newStatsDClient(addr string, prefix string, datadog bool, o *statsdOptions) (statsdClient, error) {
+	if addr == "" {
+		addr = defaultStatsDAddr
+	}
+
+	maxPacketSize := o.maxPacketSize
+	if maxPacketSize <= 0 {
+		maxPacketSize = defaultMaxPacketSize
+	}
+
+	switch o.network {
+	case "", "udp":
+		return newUDPStatsDClient(addr, prefix, datadog, maxPacketSize)
+	case "tcp", "unix", "unixgram":
+		return newConnStatsDClient(o.network, addr, prefix, maxPacketSize)
+	}
+
+	return nil, ErrUnknownStatsDNetwork
+}
+
+// dogStatsDTags formats key-value pairs as DogStatsD tags, e.g. "|#key1:value1,key2:value2"
+func dogStatsDTags(tags []string) string {
+	pairs := make([]string, 0, len(tags)/2)
+	for i := 0; i+1 < len(tags); i += 2 {
+		pairs = append(pairs, tags[i]+":"+tags[i+1])
+	}
+
+	return "|#" + strings.Join(pairs, ",")
+}
+
+// udpStatsDClient is statsdClient implementation that sends metrics over UDP with statsd library client,
+// relative gauge updates that library client does not support are sent as is over separate connection
+// right after library client buffer is flushed to keep updates order.
+type udpStatsDClient struct {
+	*statsd.Client
+
+	conn   net.Conn
+	prefix string
+	tags   string
+}
+
+func newUDPStatsDClient(addr string, prefix string, datadog bool, maxPacketSize int) (*udpStatsDClient, error) {
+	options := []statsd.Option{statsd.Address(addr), statsd.MaxPacketSize(maxPacketSize)}
+	if datadog {
+		options = append(options, statsd.TagsFormat(statsd.Datadog))
+	}
+	if prefix != "" {
+		options = append(options, statsd.Prefix(prefix))
+		prefix = strings.TrimSuffix(prefix, ".") + "."
+	}
+
+	client, err := statsd.New(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return &udpStatsDClient{Client: client, conn: conn, prefix: prefix}, nil
+}
+
+// WriteGauge sends "<prefix><bucket>:<value>|g" gauge line
+func (c *udpStatsDClient) WriteGauge(bucket string, value string) {
+	c.Client.Flush()
+
+	if _, err := c.conn.Write([]byte(c.prefix + bucket + ":" + value + "|g" + c.tags)); err != nil {
+		log.Log("An error occurred while sending gauge to statsd", map[string]interface{}{
+			"bucket": bucket,
+		}, err)
+	}
+}
+
+// WithSampleRate returns client copy that sends counters, timings and histograms with the given sample rate
+func (c *udpStatsDClient) WithSampleRate(rate float32) statsdClient {
+	return &udpStatsDClient{Client: c.Client.Clone(statsd.SampleRate(rate)), conn: c.conn, prefix: c.prefix, tags: c.tags}
+}
+
+// WithTags returns client copy that sends given key-value pairs as DogStatsD tags with every metric
+func (c *udpStatsDClient) WithTags(tags ...string) statsdClient {
+	return &udpStatsDClient{Client: c.Client.Clone(statsd.Tags(tags...)), conn: c.conn, prefix: c.prefix, tags: dogStatsDTags(tags)}
+}
+
+// Close sends buffered metrics and closes connections
+func (c *udpStatsDClient) Close() error {
+	c.Client.Close()
+	return c.conn.Close()
+}
+
+// connStatsDClient is statsdClient implementation that sends newline-terminated metrics over TCP, unix domain socket
+// or unix datagram socket connection in batches not bigger than max packet size, for datagram connection every batch
+// is sent as a single datagram. Connection is re-established with exponential backoff on write errors.
+type connStatsDClient struct {
+	conn   *reconnectingConn
+	writer *lineWriter
+	prefix string
+	rate   float32
+	tags   string
+}
+
+func newConnStatsDClient(network string, addr string, prefix string, maxPacketSize int) (*connStatsDClient, error) {
+	conn := newReconnectingConn(func() (net.Conn, error) {
+		return net.DialTimeout(network, addr, statsdDialTimeout)
+	})
+	if err := conn.Connect(); err != nil {
+		return nil, err
+	}
+
+	if prefix != "" {
+		prefix = strings.TrimSuffix(prefix, ".") + "."
+	}
+
+	return &connStatsDClient{
+		conn:   conn,
+		writer: newLineWriter(conn.Send, maxPacketSize, defaultFlushPeriod),
+		prefix: prefix,
+		rate:   1,
+	}, nil
+}
+
+func (c *connStatsDClient) write(bucket string, value string, typ string, sampled bool) {
+	line := c.prefix + bucket + ":" + value + "|" + typ
+	if sampled && c.rate != 1 {
+		if rand.Float32() > c.rate {
+			return
+		}
+		line += "|@" + strconv.FormatFloat(float64(c.rate), 'f', -1, 32)
+	}
+
+	c.writer.Write([]byte(line + c.tags + "\n"))
+}
+
+// formatStatsDValue formats metric value the same way statsd library client does
+func formatStatsDValue(value interface{}) string {
+	switch v := value.(type) {
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	}
+
+	log.Log("Unsupported statsd metric value type", map[string]interface{}{"value": value}, nil)
+	return "0"
+}
+
+// Increment increments counter by one
+func (c *connStatsDClient) Increment(bucket string) {
+	c.write(bucket, "1", "c", true)
+}
+
+// Count increments counter by n
+func (c *connStatsDClient) Count(bucket string, n interface{}) {
+	c.write(bucket, formatStatsDValue(n), "c", true)
+}
+
+// Gauge sets gauge value, negative value is sent after zero value as signed value is relative update
+func (c *connStatsDClient) Gauge(bucket string, value interface{}) {
+	formatted := formatStatsDValue(value)
+	if strings.HasPrefix(formatted, "-") {
+		c.write(bucket, "0", "g", false)
+	}
+
+	c.write(bucket, formatted, "g", false)
+}
+
+// Timing sends timing in milliseconds
+func (c *connStatsDClient) Timing(bucket string, value interface{}) {
+	c.write(bucket, formatStatsDValue(value), "ms", true)
+}
+
+// Histogram sends histogram value
+func (c *connStatsDClient) Histogram(bucket string, value interface{}) {
+	c.write(bucket, formatStatsDValue(value), "h", true)
+}
+
+// Unique sends set value
+func (c *connStatsDClient) Unique(bucket string, value string) {
+	c.write(bucket, value, "s", false)
+}
+
+// WriteGauge sends "<prefix><bucket>:<value>|g" gauge line
+func (c *connStatsDClient) WriteGauge(bucket string, value string) {
+	c.write(bucket, value, "g", false)
+}
+
+// WithSampleRate returns client copy that sends counters, timings and histograms with the given sample rate
+func (c *connStatsDClient) WithSampleRate(rate float32) statsdClient {
+	clone := *c
+	clone.rate = rate
+	return &clone
+}
+
+// WithTags returns client copy that sends given key-value pairs as DogStatsD tags with every metric
+func (c *connStatsDClient) WithTags(tags ...string) statsdClient {
+	clone := *c
+	clone.tags = dogStatsDTags(tags)
+	return &clone
+}
+
+// Flush sends buffered metrics
+func (c *connStatsDClient) Flush() {
+	c.writer.Flush()
+}
+
+// Close sends buffered metrics and closes connection
+func (c *connStatsDClient) Close() error {
+	c.writer.Close()
+	return c.conn.Close()
+}
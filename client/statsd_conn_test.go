@@ -0,0 +1,143 @@
+package client
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hellofresh/stats-go/bucket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// acceptAll accepts single connection and returns channel that receives everything read from it
+func acceptAll(t *testing.T, listener net.Listener) <-chan string {
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			received <- ""
+			return
+		}
+		defer conn.Close()
+
+		data, _ := ioutil.ReadAll(conn)
+		received <- string(data)
+	}()
+
+	return received
+}
+
+func TestStatsD_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	received := acceptAll(t, listener)
+
+	client, err := NewStatsD(listener.Addr().String(), "prefix", false, WithNetwork("tcp"))
+	require.NoError(t, err)
+
+	operation := bucket.NewMetricOperation("foo")
+	client.TrackMetric("section", operation)
+	client.TrackState("state", operation, -1)
+	client.TrackStateAdd("state", operation, 2)
+	client.TrackUnique("unique", operation, "user")
+	require.NoError(t, client.Close())
+
+	assert.Equal(t, strings.Join([]string{
+		"prefix.section.foo.-.-:1|c",
+		"prefix.total.section:1|c",
+		"prefix.state.foo.-.-:0|g",
+		"prefix.state.foo.-.-:-1|g",
+		"prefix.state.foo.-.-:+2|g",
+		"prefix.unique.foo.-.-:user|s",
+	}, "\n")+"\n", <-received)
+}
+
+func TestDogStatsD_Unix(t *testing.T) {
+	dir, err := ioutil.TempDir("", "statsd")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	socket := filepath.Join(dir, "statsd.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	defer listener.Close()
+	received := acceptAll(t, listener)
+
+	client, err := NewDogStatsD(socket, "", false, WithNetwork("unix"), WithSampleRate(0.999999))
+	require.NoError(t, err)
+
+	operation := bucket.NewMetricOperation("foo").WithLabels(map[string]string{"country": "de"})
+	client.TrackSummary("section", operation, 1.5)
+	client.TrackStateSub("section", operation, 1)
+	require.NoError(t, client.Close())
+
+	assert.Equal(t, "section.foo.-.-:1.5|h|@0.999999|#country:de\nsection.foo.-.-:-1|g|#country:de\n", <-received)
+}
+
+func TestDogStatsD_Unixgram(t *testing.T) {
+	dir, err := ioutil.TempDir("", "statsd")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	socket := filepath.Join(dir, "statsd.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client, err := NewDogStatsD(socket, "", false, WithNetwork("unixgram"), WithMaxPacketSize(64))
+	require.NoError(t, err)
+
+	operation := bucket.NewMetricOperation("foo").WithLabels(map[string]string{"country": "de"})
+	client.TrackMetric("section", operation)
+	client.TrackSummary("section", operation, 1.5)
+	client.TrackUnique("section", operation, "user")
+	require.NoError(t, client.Close())
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	var datagrams []string
+	buf := make([]byte, 1024)
+	for i := 0; i < 3; i++ {
+		n, err := conn.Read(buf)
+		require.NoError(t, err)
+		datagrams = append(datagrams, string(buf[:n]))
+	}
+
+	// every datagram is a batch of whole lines not bigger than max packet size
+	assert.Equal(t, []string{
+		"section.foo.-.-:1|c|#country:de\ntotal.section:1|c|#country:de\n",
+		"section.foo.-.-:1.5|h|#country:de\n",
+		"section.foo.-.-:user|s|#country:de\n",
+	}, datagrams)
+}
+
+func TestConnStatsDClient_MaxPacketSize(t *testing.T) {
+	var mu sync.Mutex
+	var packets []string
+	send := func(b []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		packets = append(packets, string(b))
+		return nil
+	}
+
+	client := &connStatsDClient{writer: newLineWriter(send, 20, 0), rate: 1}
+	client.Increment("foo")
+	client.Increment("bar")
+	client.Count("baz", 10)
+	client.Flush()
+
+	assert.Equal(t, []string{"foo:1|c\nbar:1|c\n", "baz:10|c\n"}, packets)
+}
+
+func TestNewStatsD_UnknownNetwork(t *testing.T) {
+	client, err := NewStatsD("127.0.0.1:8125", "", false, WithNetwork("sctp"))
+	assert.Nil(t, client)
+	assert.Equal(t, ErrUnknownStatsDNetwork, err)
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hellofresh/stats-go/bucket"
+	"github.com/hellofresh/stats-go/timer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readAllUDPLines reads lines from all the packets received until read deadline
+func readAllUDPLines(t *testing.T, conn *net.UDPConn) []string {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(100*time.Millisecond)))
+
+	var lines []string
+	buf := make([]byte, 65536)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			break
+		}
+
+		if n > 0 {
+			lines = append(lines, strings.Split(string(buf[:n]), "\n")...)
+		}
+	}
+
+	sort.Strings(lines)
+	return lines
+}
+
+func TestStatsD_Aggregation(t *testing.T) {
+	conn := listenUDP(t)
+	defer conn.Close()
+
+	client, err := NewStatsD(conn.LocalAddr().String(), "prefix", false, WithAggregation(time.Hour), WithAggregationPercentiles(50, 99.9))
+	require.NoError(t, err)
+
+	operation := bucket.NewMetricOperation("foo")
+	for i := 1; i <= 4; i++ {
+		client.TrackOperation("section", operation, timer.NewDuration(time.Duration(i)*time.Millisecond), true)
+	}
+	client.TrackMetricN("metric", operation, 5)
+	client.TrackRequest(&http.Request{Method: http.MethodGet, URL: &url.URL{Path: "/foo"}}, nil, false)
+
+	client.TrackState("state", operation, 1)
+	client.TrackStateAdd("state", operation, 2)
+	client.TrackStateAdd("delta", operation, 3)
+	client.TrackStateSub("delta", operation, 5)
+
+	require.NoError(t, client.Close())
+	// second close does not panic and does not send aggregated metrics again
+	assert.NotPanics(t, func() { client.Close() })
+
+	assert.Equal(t, []string{
+		"prefix.delta.foo.-.-:-2|g",
+		"prefix.metric.foo.-.-:5|c",
+		"prefix.request-fail.get.foo.-:1|c",
+		"prefix.request.get.foo.-:1|c",
+		"prefix.section-ok.foo.-.-.count:4|c",
+		"prefix.section-ok.foo.-.-.lower:1|g",
+		"prefix.section-ok.foo.-.-.mean:2.5|g",
+		"prefix.section-ok.foo.-.-.p50:2|g",
+		"prefix.section-ok.foo.-.-.p99_9:4|g",
+		"prefix.section-ok.foo.-.-.upper:4|g",
+		"prefix.section-ok.foo.-.-:4|c",
+		"prefix.section.foo.-.-:4|c",
+		"prefix.state.foo.-.-:3|g",
+		"prefix.total.metric:5|c",
+		"prefix.total.request-fail:1|c",
+		"prefix.total.request:1|c",
+		"prefix.total.section-ok:4|c",
+		"prefix.total.section:4|c",
+	}, readAllUDPLines(t, conn))
+}
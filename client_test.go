@@ -1,10 +1,18 @@
 package stats
 
 import (
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/hellofresh/stats-go/bucket"
 	"github.com/hellofresh/stats-go/client"
+	prom "github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewClient(t *testing.T) {
@@ -20,8 +28,165 @@ func TestNewClient(t *testing.T) {
 	assert.NoError(t, err)
 	assert.IsType(t, &client.Noop{}, statsClient)
 
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	statsClient, err = NewClient("dogstatsd://" + conn.LocalAddr().String() + "/prefix")
+	assert.NoError(t, err)
+	assert.IsType(t, &client.DogStatsD{}, statsClient)
+
+	statsClient, err = NewClient("statsd://" + conn.LocalAddr().String() + "/prefix?rate=0.5&rate.orders=0.1")
+	assert.NoError(t, err)
+	assert.IsType(t, &client.StatsD{}, statsClient)
+
+	statsClient, err = NewClient("statsd://" + conn.LocalAddr().String() + "/prefix?aggregate=1m&percentiles=50,99.9")
+	assert.NoError(t, err)
+	assert.IsType(t, &client.StatsD{}, statsClient)
+	assert.NoError(t, statsClient.Close())
+
+	statsClient, err = NewClient("statsd://" + conn.LocalAddr().String() + "/prefix?aggregate=1m&percentiles=50,foo")
+	assert.Nil(t, statsClient)
+	assert.Equal(t, ErrInvalidPercentiles, err)
+
+	statsClient, err = NewClient("dogstatsd://" + conn.LocalAddr().String() + "/prefix?rate=2")
+	assert.Nil(t, statsClient)
+	assert.Equal(t, client.ErrInvalidSampleRate, err)
+
+	statsClient, err = NewClient("influx://127.0.0.1:8089/prefix")
+	assert.NoError(t, err)
+	assert.IsType(t, &client.Influx{}, statsClient)
+
+	statsClient, err = NewClient("influx://127.0.0.1:8086/prefix?transport=http&db=telegraf")
+	assert.NoError(t, err)
+	assert.IsType(t, &client.Influx{}, statsClient)
+
+	statsClient, err = NewClient("influx://127.0.0.1:8089/prefix?transport=tcp")
+	assert.Nil(t, statsClient)
+	assert.Equal(t, ErrUnknownTransport, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	statsClient, err = NewClient("statsd+tcp://" + listener.Addr().String() + "/prefix?mtu=8192")
+	assert.NoError(t, err)
+	assert.IsType(t, &client.StatsD{}, statsClient)
+	assert.NoError(t, statsClient.Close())
+
+	closedListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedListener.Close()
+
+	statsClient, err = NewClient("statsd+tcp://" + closedListener.Addr().String() + "/prefix?retry=1m")
+	assert.NoError(t, err)
+	require.IsType(t, &client.Fallback{}, statsClient)
+	assert.False(t, statsClient.(*client.Fallback).Connected())
+	assert.IsType(t, &client.Log{}, statsClient.(*client.Fallback).Active())
+	assert.NoError(t, statsClient.Close())
+
+	dir, err := ioutil.TempDir("", "stats")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	unixListener, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: filepath.Join(dir, "statsd.sock"), Net: "unixgram"})
+	require.NoError(t, err)
+	defer unixListener.Close()
+
+	statsClient, err = NewClient("dogstatsd+unix://" + filepath.Join(dir, "statsd.sock") + "?prefix=my.app")
+	assert.NoError(t, err)
+	assert.IsType(t, &client.DogStatsD{}, statsClient)
+	assert.NoError(t, statsClient.Close())
+
+	statsClient, err = NewClient("graphite://" + listener.Addr().String() + "/prefix?flush=1m")
+	assert.NoError(t, err)
+	assert.IsType(t, &client.Graphite{}, statsClient)
+	assert.NoError(t, statsClient.Close())
+
+	statsClient, err = NewClient("otlp://127.0.0.1:4318/namespace?service=app&interval=1m")
+	assert.NoError(t, err)
+	assert.IsType(t, &client.OTLP{}, statsClient)
+
+	statsClient, err = NewClient("prometheus://namespace")
+	assert.NoError(t, err)
+	assert.IsType(t, &client.Prometheus{}, statsClient)
+
+	statsClient, err = NewClient("prometheus://namespace?push=http://127.0.0.1:9091&job=batch&push_interval=1m")
+	assert.NoError(t, err)
+	assert.IsType(t, &client.Prometheus{}, statsClient)
+
+	statsClient, err = NewClient("prometheus://namespace?buckets=linear:0.1,0.1,10&buckets.db=0.001,0.01,0.1")
+	assert.NoError(t, err)
+	assert.IsType(t, &client.Prometheus{}, statsClient)
+
+	statsClient, err = NewClient("prometheus://namespace?buckets=exponential:0,2,10")
+	assert.Nil(t, statsClient)
+	assert.Equal(t, client.ErrInvalidHistogramBuckets, err)
+
+	statsClient, err = NewClient("memory://?depth=5")
+	assert.NoError(t, err)
+	require.IsType(t, &client.Memory{}, statsClient)
+	statsClient.TrackMetric("section", bucket.NewMetricOperation("a", "b", "c", "d"))
+	assert.Equal(t, 1, statsClient.(*client.Memory).CountMetrics["section.a.b.c.d.-"])
+
+	statsClient, err = NewClient("memory://?depth=0")
+	assert.Nil(t, statsClient)
+	assert.Equal(t, ErrInvalidOperationDepth, err)
+
+	statsClient, err = NewClient("prometheus://namespace?max_series=100")
+	assert.NoError(t, err)
+	assert.IsType(t, &client.Prometheus{}, statsClient)
+
+	statsClient, err = NewClient("prometheus://namespace?max_series=-1")
+	assert.Nil(t, statsClient)
+	assert.Equal(t, ErrInvalidMaxSeries, err)
+
+	statsClient, err = NewClient("prometheus://dedicated?registry=dedicated")
+	assert.NoError(t, err)
+	assert.IsType(t, &client.Prometheus{}, statsClient)
+
+	statsClient, err = NewClient("prometheus://namespace?registry=custom")
+	assert.Nil(t, statsClient)
+	assert.Equal(t, ErrInvalidRegistry, err)
+
+	statsClient, err = NewClient("multi://?dsn=memory://&dsn=" + url.QueryEscape("prometheus://namespace?buckets=0.1,1"))
+	assert.NoError(t, err)
+	require.IsType(t, &client.Multi{}, statsClient)
+	assert.Len(t, statsClient.(*client.Multi).Clients(), 2)
+
+	statsClient, err = NewClient("multi://?dsn=memory://&dsn=unknown://")
+	assert.Nil(t, statsClient)
+	assert.Equal(t, ErrUnknownClient, err)
+
+	statsClient, err = NewClient("multi://")
+	assert.Nil(t, statsClient)
+	assert.Equal(t, ErrNoClients, err)
+
 	statsClient, err = NewClient("unknown://")
 	assert.Nil(t, statsClient)
 	assert.Error(t, err)
 	assert.Equal(t, ErrUnknownClient, err)
 }
+
+func TestNewClient_PrometheusRegistry(t *testing.T) {
+	gathered := func(name string) bool {
+		families, err := prom.DefaultGatherer.Gather()
+		require.NoError(t, err)
+		for _, family := range families {
+			if family.GetName() == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	statsClient, err := NewClient("prometheus://default_registry")
+	require.NoError(t, err)
+	statsClient.TrackMetric("section", bucket.NewMetricOperation("foo"))
+	assert.True(t, gathered("default_registry_section_foo"))
+
+	statsClient, err = NewClient("prometheus://dedicated_registry?registry=dedicated")
+	require.NoError(t, err)
+	statsClient.TrackMetric("section", bucket.NewMetricOperation("foo"))
+	assert.False(t, gathered("dedicated_registry_section_foo"))
+}
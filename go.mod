@@ -5,6 +5,7 @@ go 1.14
 require (
 	github.com/felixge/httpsnoop v1.0.1
 	github.com/prometheus/client_golang v1.5.1
+	github.com/prometheus/client_model v0.2.0
 	github.com/rainycape/unidecode v0.0.0-20150907023854-cb7f23ec59be
 	github.com/sirupsen/logrus v1.5.0
 	github.com/stretchr/testify v1.5.1
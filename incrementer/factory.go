@@ -2,7 +2,7 @@ package incrementer
 
 // CounterFactory interface for making new CounterVec instances
 type CounterFactory interface {
-	Create(metric string, labelKeys []string) CounterVec
+	Create(metric string, labelKeys []string) (CounterVec, error)
 }
 
 // Factory interface for making new incrementer instances
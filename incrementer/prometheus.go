@@ -1,18 +1,29 @@
 package incrementer
 
 import (
+	"errors"
 	"sync"
 
 	"github.com/hellofresh/stats-go/bucket"
+	"github.com/hellofresh/stats-go/internal/labelset"
+	"github.com/hellofresh/stats-go/log"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-// Prometheus struct is Incrementer interface implementation that writes all metrics to Prometheus
+// ErrLabelNamesMismatch is an error returned when metric is tracked with label names different
+// from the ones its counter vector was created with
+var ErrLabelNamesMismatch = errors.New("label names differ from the ones metric was created with")
+
+// Prometheus struct is Incrementer interface implementation that writes all metrics to Prometheus.
+// Counter vector is created with sorted label names on the first increment and label values are always passed
+// in the same sorted order, so series are stable regardless of map iteration order.
 type Prometheus struct {
 	sync.Mutex
 
 	counter        CounterVec
+	labelNames     []string
 	counterFactory CounterFactory
+	createFailed   bool
 }
 
 // CounterVec interface for counter vectors in prometheus backend
@@ -24,15 +35,25 @@ type CounterVec interface {
 }
 
 // PrometheusCounterFactory implements CounterFactory interface
-type PrometheusCounterFactory struct{}
+type PrometheusCounterFactory struct {
+	registerer prometheus.Registerer
+}
 
-// NewPrometheusCounterFactory returns new PrometheusCounterFactory instance
+// NewPrometheusCounterFactory returns new PrometheusCounterFactory instance that registers counters in default registry
 func NewPrometheusCounterFactory() *PrometheusCounterFactory {
-	return &PrometheusCounterFactory{}
+	return NewPrometheusCounterFactoryWithRegisterer(prometheus.DefaultRegisterer)
 }
 
-// Create method returns new CounterVec instance with metric and labelKeys attributes
-func (f *PrometheusCounterFactory) Create(metric string, labelKeys []string) CounterVec {
+// NewPrometheusCounterFactoryWithRegisterer returns new PrometheusCounterFactory instance
+// that registers counters with the given registerer
+func NewPrometheusCounterFactoryWithRegisterer(registerer prometheus.Registerer) *PrometheusCounterFactory {
+	return &PrometheusCounterFactory{registerer: registerer}
+}
+
+// Create method returns new CounterVec instance with metric and labelKeys attributes.
+// If the same counter is already registered - existing instance is returned,
+// other registration errors, e.g. the same metric with different label names, are returned as is.
+func (f *PrometheusCounterFactory) Create(metric string, labelKeys []string) (CounterVec, error) {
 	p := prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: metric,
@@ -40,22 +61,39 @@ func (f *PrometheusCounterFactory) Create(metric string, labelKeys []string) Cou
 		},
 		labelKeys,
 	)
-	prometheus.Register(p)
 
-	return p
+	if err := f.registerer.Register(p); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			if existing, ok := are.ExistingCollector.(*prometheus.CounterVec); ok {
+				return existing, nil
+			}
+		}
+		return nil, err
+	}
+
+	return p, nil
 }
 
 // PrometheusIncrementerFactory implements Factory interface
-type PrometheusIncrementerFactory struct{}
+type PrometheusIncrementerFactory struct {
+	registerer prometheus.Registerer
+}
 
 // NewPrometheusIncrementerFactory returns new NewPrometheusIncrementerFactory instance
+// that registers counters in default registry
 func NewPrometheusIncrementerFactory() *PrometheusIncrementerFactory {
-	return &PrometheusIncrementerFactory{}
+	return NewPrometheusIncrementerFactoryWithRegisterer(prometheus.DefaultRegisterer)
+}
+
+// NewPrometheusIncrementerFactoryWithRegisterer returns new NewPrometheusIncrementerFactory instance
+// that registers counters with the given registerer
+func NewPrometheusIncrementerFactoryWithRegisterer(registerer prometheus.Registerer) *PrometheusIncrementerFactory {
+	return &PrometheusIncrementerFactory{registerer: registerer}
 }
 
 // Create method returns new Prometheus incrementer instance
 func (p *PrometheusIncrementerFactory) Create() Incrementer {
-	return NewPrometheus(NewPrometheusCounterFactory())
+	return NewPrometheus(NewPrometheusCounterFactoryWithRegisterer(p.registerer))
 }
 
 // NewPrometheus creates new prometheus incrementer instance
@@ -63,48 +101,66 @@ func NewPrometheus(counterFactory CounterFactory) *Prometheus {
 	return &Prometheus{counter: nil, counterFactory: counterFactory}
 }
 
-// Increment increments metric in prometheus
-func (i *Prometheus) Increment(metric string, labels ...map[string]string) {
-	var labelNames []string
-	var labelValues []string
-
-	if labels != nil {
-		for k, v := range labels[0] {
-			labelNames = append(labelNames, k)
-			labelValues = append(labelValues, v)
-		}
-	}
+// getCounter creates counter vector if it was not created before and returns counter for given labels.
+// Counter vector creation is not retried if it failed before, nil counter is returned without error in this case,
+// so that the error, e.g. the same metric registered with different label names, is logged only once.
+func (i *Prometheus) getCounter(metric string, labels []map[string]string) (prometheus.Counter, error) {
+	labelNames, labelValues := labelset.Sorted(labels...)
 
 	i.Lock()
 	defer i.Unlock()
 
 	if i.counter == nil {
-		i.counter = i.counterFactory.Create(metric, labelNames)
+		if i.createFailed {
+			return nil, nil
+		}
+
+		counter, err := i.counterFactory.Create(metric, labelNames)
+		if err != nil {
+			i.createFailed = true
+			return nil, err
+		}
+		i.counter = counter
+		i.labelNames = labelNames
+	}
+
+	if !labelset.Equal(i.labelNames, labelNames) {
+		return nil, ErrLabelNamesMismatch
 	}
 
-	i.counter.WithLabelValues(labelValues...).Inc()
+	return i.counter.GetMetricWithLabelValues(labelValues...)
 }
 
-// IncrementN increments metric by n in prometheus
-func (i *Prometheus) IncrementN(metric string, n int, labels ...map[string]string) {
-	var labelNames []string
-	var labelValues []string
+func logCounterError(metric string, err error) {
+	log.Log("An error occurred while incrementing prometheus counter", map[string]interface{}{
+		"metric": metric,
+	}, err)
+}
 
-	if labels != nil {
-		for k, v := range labels[0] {
-			labelNames = append(labelNames, k)
-			labelValues = append(labelValues, v)
-		}
+// Increment increments metric in prometheus
+func (i *Prometheus) Increment(metric string, labels ...map[string]string) {
+	counter, err := i.getCounter(metric, labels)
+	if err != nil {
+		logCounterError(metric, err)
+	}
+	if counter == nil {
+		return
 	}
 
-	i.Lock()
-	defer i.Unlock()
+	counter.Inc()
+}
 
-	if i.counter == nil {
-		i.counter = i.counterFactory.Create(metric, labelNames)
+// IncrementN increments metric by n in prometheus
+func (i *Prometheus) IncrementN(metric string, n int, labels ...map[string]string) {
+	counter, err := i.getCounter(metric, labels)
+	if err != nil {
+		logCounterError(metric, err)
+	}
+	if counter == nil {
+		return
 	}
 
-	i.counter.WithLabelValues(labelValues...).Add(float64(n))
+	counter.Add(float64(n))
 }
 
 // IncrementAll increments all metrics for given bucket in prometheus
@@ -1,11 +1,13 @@
 package incrementer
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/hellofresh/stats-go/bucket"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type CounterMock struct {
@@ -25,7 +27,7 @@ type CounterVecMock struct {
 }
 
 func (m *CounterVecMock) GetMetricWithLabelValues(lvs ...string) (prometheus.Counter, error) {
-	return nil, nil
+	return m.WithLabelValues(lvs...), nil
 }
 
 func (m *CounterVecMock) GetMetricWith(labels prometheus.Labels) (prometheus.Counter, error) {
@@ -46,9 +48,18 @@ type CounterFactoryMock struct {
 	mock CounterVecMock
 }
 
-func (m *CounterFactoryMock) Create(metric string, labelKeys []string) CounterVec {
+func (m *CounterFactoryMock) Create(metric string, labelKeys []string) (CounterVec, error) {
 	m.mock = CounterVecMock{values: labelKeys}
-	return &m.mock
+	return &m.mock, nil
+}
+
+type FailingCounterFactoryMock struct {
+	calls int
+}
+
+func (m *FailingCounterFactoryMock) Create(metric string, labelKeys []string) (CounterVec, error) {
+	m.calls++
+	return nil, errors.New("registration failed")
 }
 
 func TestPrometheus_Increment(t *testing.T) {
@@ -90,3 +101,109 @@ func TestPrometheus_IncrementNWithLabels(t *testing.T) {
 	assert.Equal(t, 1, m.mock.withLabelValuesCalls)
 	assert.Equal(t, 2, len(m.mock.values))
 }
+
+func TestPrometheusCounterFactory_Create(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	f := NewPrometheusCounterFactoryWithRegisterer(registry)
+
+	c1, err := f.Create("section_foo", []string{"key1"})
+	require.NoError(t, err)
+
+	c2, err := f.Create("section_foo", []string{"key1"})
+	require.NoError(t, err)
+	assert.True(t, c1 == c2)
+
+	_, err = f.Create("section_foo", []string{"key1", "key2"})
+	assert.Error(t, err)
+}
+
+func TestPrometheus_IncrementRegistry(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	f := NewPrometheusIncrementerFactoryWithRegisterer(registry)
+
+	f.Create().Increment("section_foo", map[string]string{"key1": "value1"})
+	f.Create().IncrementN("section_foo", 2, map[string]string{"key1": "value1"})
+	// label names mismatch must not panic, error is logged and metric is skipped
+	f.Create().Increment("section_foo", map[string]string{"key2": "value2"})
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(families))
+	assert.Equal(t, "section_foo", families[0].GetName())
+	require.Equal(t, 1, len(families[0].GetMetric()))
+	assert.Equal(t, float64(3), families[0].GetMetric()[0].GetCounter().GetValue())
+}
+
+func TestPrometheus_IncrementSortedLabels(t *testing.T) {
+	m := &CounterFactoryMock{}
+	i := NewPrometheus(m)
+
+	labels := map[string]string{"c": "3", "a": "1", "d": "4", "b": "2"}
+	for n := 0; n < 50; n++ {
+		i.Increment("section_foo", labels)
+		assert.Equal(t, []string{"1", "2", "3", "4"}, m.mock.values)
+	}
+	assert.Equal(t, 50, m.mock.withLabelValuesCalls)
+}
+
+func TestPrometheus_IncrementLabelNamesMismatch(t *testing.T) {
+	m := &CounterFactoryMock{}
+	i := NewPrometheus(m)
+
+	_, err := i.getCounter("section_foo", []map[string]string{{"key1": "value1", "key2": "value2"}})
+	require.NoError(t, err)
+
+	_, err = i.getCounter("section_foo", []map[string]string{{"key2": "value2", "key1": "value1"}})
+	assert.NoError(t, err)
+
+	_, err = i.getCounter("section_foo", []map[string]string{{"key1": "value1", "key3": "value3"}})
+	assert.Equal(t, ErrLabelNamesMismatch, err)
+
+	_, err = i.getCounter("section_foo", nil)
+	assert.Equal(t, ErrLabelNamesMismatch, err)
+	assert.Equal(t, 2, m.mock.withLabelValuesCalls)
+}
+
+func TestPrometheus_IncrementStableSeries(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	i := NewPrometheusIncrementerFactoryWithRegisterer(registry).Create()
+
+	for n := 0; n < 100; n++ {
+		i.Increment("section_foo", map[string]string{"method": "get", "path": "users", "status": "200", "host": "a"})
+		i.Increment("section_foo", map[string]string{"method": "post", "path": "orders", "status": "500", "host": "b"})
+	}
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(families))
+	require.Equal(t, 2, len(families[0].GetMetric()))
+	for _, metric := range families[0].GetMetric() {
+		labels := map[string]string{}
+		for _, pair := range metric.GetLabel() {
+			labels[pair.GetName()] = pair.GetValue()
+		}
+
+		if labels["method"] == "get" {
+			assert.Equal(t, map[string]string{"method": "get", "path": "users", "status": "200", "host": "a"}, labels)
+		} else {
+			assert.Equal(t, map[string]string{"method": "post", "path": "orders", "status": "500", "host": "b"}, labels)
+		}
+		assert.Equal(t, float64(100), metric.GetCounter().GetValue())
+	}
+}
+
+func TestPrometheus_IncrementCreateFailedOnce(t *testing.T) {
+	m := &FailingCounterFactoryMock{}
+	i := NewPrometheus(m)
+
+	_, err := i.getCounter("section_foo", []map[string]string{{"key1": "value1"}})
+	assert.Error(t, err)
+
+	// creation is not retried and error is not returned again to be logged once
+	counter, err := i.getCounter("section_foo", []map[string]string{{"key1": "value1"}})
+	assert.NoError(t, err)
+	assert.Nil(t, counter)
+
+	i.Increment("section_foo", map[string]string{"key1": "value1"})
+	assert.Equal(t, 1, m.calls)
+}
@@ -2,16 +2,21 @@ package incrementer
 
 import (
 	"github.com/hellofresh/stats-go/bucket"
-	"gopkg.in/alexcesaro/statsd.v2"
 )
 
+// StatsDClient is a subset of statsd client methods StatsD incrementer uses, it is implemented by *statsd.Client
+type StatsDClient interface {
+	Increment(bucket string)
+	Count(bucket string, n interface{})
+}
+
 // StatsD struct is Incrementer interface implementation that writes all metrics to statsd
 type StatsD struct {
-	c *statsd.Client
+	c StatsDClient
 }
 
 // NewStatsD creates new statsd incrementer instance
-func NewStatsD(c *statsd.Client) *StatsD {
+func NewStatsD(c StatsDClient) *StatsD {
 	return &StatsD{c: c}
 }
 
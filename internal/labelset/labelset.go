@@ -0,0 +1,45 @@
+// Package labelset contains helpers for metric labels sets shared by backends that need stable label order
+package labelset
+
+import "sort"
+
+// Keys returns map keys sorted alphabetically
+func Keys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Sorted returns label names sorted alphabetically and label values in the same order.
+// Labels are passed the same way they are passed to incrementers and states, only the first map is used.
+func Sorted(labels ...map[string]string) ([]string, []string) {
+	if len(labels) == 0 || len(labels[0]) == 0 {
+		return nil, nil
+	}
+
+	names := Keys(labels[0])
+	values := make([]string, 0, len(names))
+	for _, k := range names {
+		values = append(values, labels[0][k])
+	}
+
+	return names, values
+}
+
+// Equal checks if both sorted label names lists are the same
+func Equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,33 @@
+package labelset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeys(t *testing.T) {
+	assert.Equal(t, []string{"a", "b", "c"}, Keys(map[string]string{"c": "3", "a": "1", "b": "2"}))
+	assert.Empty(t, Keys(nil))
+}
+
+func TestSorted(t *testing.T) {
+	names, values := Sorted(map[string]string{"success": "true", "country": "de"}, map[string]string{"ignored": "1"})
+	assert.Equal(t, []string{"country", "success"}, names)
+	assert.Equal(t, []string{"de", "true"}, values)
+
+	names, values = Sorted()
+	assert.Nil(t, names)
+	assert.Nil(t, values)
+
+	names, values = Sorted(map[string]string{})
+	assert.Nil(t, names)
+	assert.Nil(t, values)
+}
+
+func TestEqual(t *testing.T) {
+	assert.True(t, Equal(nil, []string{}))
+	assert.True(t, Equal([]string{"a", "b"}, []string{"a", "b"}))
+	assert.False(t, Equal([]string{"a", "b"}, []string{"a"}))
+	assert.False(t, Equal([]string{"a", "b"}, []string{"a", "c"}))
+}
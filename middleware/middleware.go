@@ -4,13 +4,30 @@ import (
 	"net/http"
 
 	"github.com/felixge/httpsnoop"
+	"github.com/hellofresh/stats-go/bucket"
 	"github.com/hellofresh/stats-go/client"
 	"github.com/hellofresh/stats-go/context"
 	"github.com/hellofresh/stats-go/timer"
 )
 
+// Option is a function that configures stats middleware
+type Option func(s client.Client)
+
+// WithRoutePattern makes client name HTTP Request metrics after route pattern matched by router,
+// e.g. "request.get.users.{id}", instead of raw request path, see bucket.NewRoutePatternCallback.
+// Client HTTP metric callback that is already set is used for requests that have no route pattern matched.
+func WithRoutePattern(extractors ...bucket.RoutePatternExtractor) Option {
+	return func(s client.Client) {
+		s.SetHTTPMetricCallback(bucket.NewRoutePatternCallback(s.GetHTTPMetricCallback(), extractors...))
+	}
+}
+
 // New creates a new stats middleware
-func New(s client.Client) func(http.Handler) http.Handler {
+func New(s client.Client, opts ...Option) func(http.Handler) http.Handler {
+	for _, opt := range opts {
+		opt(s)
+	}
+
 	return func(handler http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			r = r.WithContext(context.New(r.Context(), s))
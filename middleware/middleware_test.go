@@ -1,10 +1,12 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/hellofresh/stats-go/bucket"
 	"github.com/hellofresh/stats-go/client"
 	"github.com/stretchr/testify/assert"
 )
@@ -45,3 +47,39 @@ func ping(w http.ResponseWriter, r *http.Request) {
 	w.Header().Add("Content-Type", "application/json")
 	w.Write([]byte("OK\n"))
 }
+
+type routeContextMock struct {
+	pattern string
+}
+
+func (m *routeContextMock) RoutePattern() string {
+	return m.pattern
+}
+
+type routeContextKey struct{}
+
+func TestMiddleware_WithRoutePattern(t *testing.T) {
+	mClient := client.NewMemory(false)
+	mClient.SetHTTPMetricCallback(bucket.NewHasIDAtSecondLevelCallback(&bucket.SecondLevelIDConfig{
+		HasIDAtSecondLevel: bucket.SectionsTestsMap{"orders": {Name: bucket.SectionTestTrue, Callback: bucket.TestAlwaysTrue}},
+	}))
+	mw := New(mClient, WithRoutePattern(bucket.NewContextRoutePatternExtractor(routeContextKey{})))
+
+	// router fills matched pattern in the context created before stats middleware is called, e.g. chi router
+	router := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rctx, ok := r.Context().Value(routeContextKey{}).(*routeContextMock); ok && r.URL.Path != "/orders/123" {
+			rctx.pattern = "/users/{id}/orders"
+		}
+		ping(w, r)
+	})
+
+	for _, path := range []string{"/users/123/orders", "/users/456/orders", "/orders/123"} {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r = r.WithContext(context.WithValue(r.Context(), routeContextKey{}, &routeContextMock{}))
+		mw(router).ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	assert.Equal(t, 2, mClient.CountMetrics["request.get.users.{id}"])
+	assert.Equal(t, 1, mClient.CountMetrics["request.get.orders.-id-"])
+	assert.Equal(t, 0, mClient.CountMetrics["request.get.users.123"])
+}
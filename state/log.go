@@ -14,3 +14,27 @@ func (s *Log) Set(metric string, n int, labels ...map[string]string) {
 		"state":  n,
 	}, nil)
 }
+
+// SetFloat sets metric state to float value
+func (s *Log) SetFloat(metric string, value float64, labels ...map[string]string) {
+	log.Log("Stats state set", map[string]interface{}{
+		"bucket": metric,
+		"state":  value,
+	}, nil)
+}
+
+// Add increases metric state by delta
+func (s *Log) Add(metric string, delta float64, labels ...map[string]string) {
+	log.Log("Stats state increased", map[string]interface{}{
+		"bucket": metric,
+		"delta":  delta,
+	}, nil)
+}
+
+// Sub decreases metric state by delta
+func (s *Log) Sub(metric string, delta float64, labels ...map[string]string) {
+	log.Log("Stats state decreased", map[string]interface{}{
+		"bucket": metric,
+		"delta":  delta,
+	}, nil)
+}
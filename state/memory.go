@@ -2,20 +2,42 @@ package state
 
 // Memory struct is State interface implementation that stores results in memory for further usage
 type Memory struct {
-	metrics map[string]int
+	metrics      map[string]int
+	floatMetrics map[string]float64
 }
 
 // NewMemory builds and returns new Memory instance
 func NewMemory() *Memory {
-	return &Memory{make(map[string]int)}
+	return &Memory{make(map[string]int), make(map[string]float64)}
 }
 
 // Set sets metric state
 func (i *Memory) Set(metric string, n int, labels ...map[string]string) {
-	i.metrics[metric] = n
+	i.SetFloat(metric, float64(n))
 }
 
-// Metrics returns all previously stored metrics
+// SetFloat sets metric state to float value
+func (i *Memory) SetFloat(metric string, value float64, labels ...map[string]string) {
+	i.floatMetrics[metric] = value
+	i.metrics[metric] = int(value)
+}
+
+// Add increases metric state by delta
+func (i *Memory) Add(metric string, delta float64, labels ...map[string]string) {
+	i.SetFloat(metric, i.floatMetrics[metric]+delta)
+}
+
+// Sub decreases metric state by delta
+func (i *Memory) Sub(metric string, delta float64, labels ...map[string]string) {
+	i.SetFloat(metric, i.floatMetrics[metric]-delta)
+}
+
+// Metrics returns all previously stored metrics, float values are truncated to integers
 func (i *Memory) Metrics() map[string]int {
 	return i.metrics
 }
+
+// FloatMetrics returns all previously stored metrics
+func (i *Memory) FloatMetrics() map[string]float64 {
+	return i.floatMetrics
+}
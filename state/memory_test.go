@@ -26,3 +26,19 @@ func TestNewMemory(t *testing.T) {
 	memory.Set(metric1, metricState12)
 	assert.Equal(t, metricState12, metrics[metric1])
 }
+
+func TestMemory_Float(t *testing.T) {
+	memory := NewMemory()
+
+	memory.SetFloat("metric1", 1.5)
+	memory.Add("metric1", 2)
+	memory.Sub("metric2", 0.5)
+
+	assert.Equal(t, 3.5, memory.FloatMetrics()["metric1"])
+	assert.Equal(t, 3, memory.Metrics()["metric1"])
+	assert.Equal(t, -0.5, memory.FloatMetrics()["metric2"])
+	assert.Equal(t, 0, memory.Metrics()["metric2"])
+
+	memory.Set("metric1", 10)
+	assert.Equal(t, float64(10), memory.FloatMetrics()["metric1"])
+}
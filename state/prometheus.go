@@ -1,17 +1,28 @@
 package state
 
 import (
+	"errors"
 	"sync"
 
+	"github.com/hellofresh/stats-go/internal/labelset"
+	"github.com/hellofresh/stats-go/log"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-// Prometheus struct is State interface implementation that writes all states
+// ErrLabelNamesMismatch is an error returned when metric is tracked with label names different
+// from the ones its gauge vector was created with
+var ErrLabelNamesMismatch = errors.New("label names differ from the ones metric was created with")
+
+// Prometheus struct is State interface implementation that writes all states.
+// Gauge vector is created with sorted label names on the first update and label values are always passed
+// in the same sorted order, so series are stable regardless of map iteration order.
 type Prometheus struct {
 	sync.Mutex
 
 	gauge        GaugeVec
+	labelNames   []string
 	gaugeFactory GaugeFactory
+	createFailed bool
 }
 
 // GaugeVec interface for gauge vectors in prometheus backend
@@ -24,19 +35,29 @@ type GaugeVec interface {
 
 // GaugeFactory interface for making new GaugeVec instances
 type GaugeFactory interface {
-	Create(metric string, labelKeys []string) GaugeVec
+	Create(metric string, labelKeys []string) (GaugeVec, error)
 }
 
 // PrometheusGaugeFactory implements GaugeFactory interface
-type PrometheusGaugeFactory struct{}
+type PrometheusGaugeFactory struct {
+	registerer prometheus.Registerer
+}
 
-// NewPrometheusGaugeFactory returns new PrometheusGaugeFactory instance
+// NewPrometheusGaugeFactory returns new PrometheusGaugeFactory instance that registers gauges in default registry
 func NewPrometheusGaugeFactory() *PrometheusGaugeFactory {
-	return &PrometheusGaugeFactory{}
+	return NewPrometheusGaugeFactoryWithRegisterer(prometheus.DefaultRegisterer)
 }
 
-// Create method returns new GaugeVec instance with metric and labelKeys attributes
-func (f *PrometheusGaugeFactory) Create(metric string, labelKeys []string) GaugeVec {
+// NewPrometheusGaugeFactoryWithRegisterer returns new PrometheusGaugeFactory instance
+// that registers gauges with the given registerer
+func NewPrometheusGaugeFactoryWithRegisterer(registerer prometheus.Registerer) *PrometheusGaugeFactory {
+	return &PrometheusGaugeFactory{registerer: registerer}
+}
+
+// Create method returns new GaugeVec instance with metric and labelKeys attributes.
+// If the same gauge is already registered - existing instance is returned,
+// other registration errors, e.g. the same metric with different label names, are returned as is.
+func (f *PrometheusGaugeFactory) Create(metric string, labelKeys []string) (GaugeVec, error) {
 	p := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: metric,
@@ -44,22 +65,38 @@ func (f *PrometheusGaugeFactory) Create(metric string, labelKeys []string) Gauge
 		},
 		labelKeys,
 	)
-	prometheus.Register(p)
 
-	return p
+	if err := f.registerer.Register(p); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			if existing, ok := are.ExistingCollector.(*prometheus.GaugeVec); ok {
+				return existing, nil
+			}
+		}
+		return nil, err
+	}
+
+	return p, nil
 }
 
 // PrometheusStateFactory implements Factory interface
-type PrometheusStateFactory struct{}
+type PrometheusStateFactory struct {
+	registerer prometheus.Registerer
+}
 
-// NewPrometheusStateFactory returns new NewPrometheusIncrementerFactory instance
+// NewPrometheusStateFactory returns new NewPrometheusStateFactory instance that registers gauges in default registry
 func NewPrometheusStateFactory() *PrometheusStateFactory {
-	return &PrometheusStateFactory{}
+	return NewPrometheusStateFactoryWithRegisterer(prometheus.DefaultRegisterer)
+}
+
+// NewPrometheusStateFactoryWithRegisterer returns new NewPrometheusStateFactory instance
+// that registers gauges with the given registerer
+func NewPrometheusStateFactoryWithRegisterer(registerer prometheus.Registerer) *PrometheusStateFactory {
+	return &PrometheusStateFactory{registerer: registerer}
 }
 
 // Create method returns new Prometheus incrementer instance
 func (p *PrometheusStateFactory) Create() State {
-	return NewPrometheus(NewPrometheusGaugeFactory())
+	return NewPrometheus(NewPrometheusGaugeFactoryWithRegisterer(p.registerer))
 }
 
 // NewPrometheus creates new prometheus state instance
@@ -67,24 +104,82 @@ func NewPrometheus(gaugeFactory GaugeFactory) *Prometheus {
 	return &Prometheus{gauge: nil, gaugeFactory: gaugeFactory}
 }
 
+// getGauge creates gauge vector if it was not created before and returns gauge for given labels.
+// Gauge vector creation is not retried if it failed before, nil gauge is returned without error in this case,
+// so that the error, e.g. the same metric registered with different label names, is logged only once.
+func (s *Prometheus) getGauge(metric string, labels []map[string]string) (prometheus.Gauge, error) {
+	labelNames, labelValues := labelset.Sorted(labels...)
+
+	s.Lock()
+	defer s.Unlock()
+
+	if s.gauge == nil {
+		if s.createFailed {
+			return nil, nil
+		}
+
+		gauge, err := s.gaugeFactory.Create(metric, labelNames)
+		if err != nil {
+			s.createFailed = true
+			return nil, err
+		}
+		s.gauge = gauge
+		s.labelNames = labelNames
+	}
+
+	if !labelset.Equal(s.labelNames, labelNames) {
+		return nil, ErrLabelNamesMismatch
+	}
+
+	return s.gauge.GetMetricWithLabelValues(labelValues...)
+}
+
+func logGaugeError(metric string, err error) {
+	log.Log("An error occurred while setting prometheus gauge", map[string]interface{}{
+		"metric": metric,
+	}, err)
+}
+
 // Set sets metric state
 func (s *Prometheus) Set(metric string, n int, labels ...map[string]string) {
-	var labelNames []string
-	var labelValues []string
+	s.SetFloat(metric, float64(n), labels...)
+}
 
-	if labels != nil {
-		for k, v := range labels[0] {
-			labelNames = append(labelNames, k)
-			labelValues = append(labelValues, v)
-		}
+// SetFloat sets metric state to float value
+func (s *Prometheus) SetFloat(metric string, value float64, labels ...map[string]string) {
+	gauge, err := s.getGauge(metric, labels)
+	if err != nil {
+		logGaugeError(metric, err)
+	}
+	if gauge == nil {
+		return
 	}
 
-	s.Lock()
-	defer s.Unlock()
+	gauge.Set(value)
+}
 
-	if s.gauge == nil {
-		s.gauge = s.gaugeFactory.Create(metric, labelNames)
+// Add increases metric state by delta
+func (s *Prometheus) Add(metric string, delta float64, labels ...map[string]string) {
+	gauge, err := s.getGauge(metric, labels)
+	if err != nil {
+		logGaugeError(metric, err)
+	}
+	if gauge == nil {
+		return
+	}
+
+	gauge.Add(delta)
+}
+
+// Sub decreases metric state by delta
+func (s *Prometheus) Sub(metric string, delta float64, labels ...map[string]string) {
+	gauge, err := s.getGauge(metric, labels)
+	if err != nil {
+		logGaugeError(metric, err)
+	}
+	if gauge == nil {
+		return
 	}
 
-	s.gauge.WithLabelValues(labelValues...).Set(float64(n))
+	gauge.Sub(delta)
 }
@@ -1,10 +1,12 @@
 package state
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type GaugeMock struct {
@@ -36,7 +38,7 @@ type GaugeVecMock struct {
 }
 
 func (m *GaugeVecMock) GetMetricWithLabelValues(lvs ...string) (prometheus.Gauge, error) {
-	return nil, nil
+	return m.WithLabelValues(lvs...), nil
 }
 
 func (m *GaugeVecMock) GetMetricWith(labels prometheus.Labels) (prometheus.Gauge, error) {
@@ -57,9 +59,18 @@ type GaugeFactoryMock struct {
 	mock GaugeVecMock
 }
 
-func (m *GaugeFactoryMock) Create(metric string, labelKeys []string) GaugeVec {
+func (m *GaugeFactoryMock) Create(metric string, labelKeys []string) (GaugeVec, error) {
 	m.mock = GaugeVecMock{values: labelKeys, gaugeMock: GaugeMock{}}
-	return &m.mock
+	return &m.mock, nil
+}
+
+type FailingGaugeFactoryMock struct {
+	calls int
+}
+
+func (m *FailingGaugeFactoryMock) Create(metric string, labelKeys []string) (GaugeVec, error) {
+	m.calls++
+	return nil, errors.New("registration failed")
 }
 
 func TestPrometheus_Set(t *testing.T) {
@@ -89,3 +100,117 @@ func TestPrometheus_SetWithLabels(t *testing.T) {
 	assert.Equal(t, true, m.mock.gaugeMock.setCalled)
 	assert.Equal(t, float64(10), m.mock.gaugeMock.setCalledValue)
 }
+
+func TestPrometheusGaugeFactory_Create(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	f := NewPrometheusGaugeFactoryWithRegisterer(registry)
+
+	g1, err := f.Create("section_foo", []string{"key1"})
+	require.NoError(t, err)
+
+	g2, err := f.Create("section_foo", []string{"key1"})
+	require.NoError(t, err)
+	assert.True(t, g1 == g2)
+
+	_, err = f.Create("section_foo", []string{"key1", "key2"})
+	assert.Error(t, err)
+}
+
+func TestPrometheus_SetRegistry(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	f := NewPrometheusStateFactoryWithRegisterer(registry)
+
+	f.Create().Set("section_foo", 10, map[string]string{"key1": "value1"})
+	// label names mismatch must not panic, error is logged and metric is skipped
+	f.Create().Set("section_foo", 20, map[string]string{"key2": "value2"})
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(families))
+	require.Equal(t, 1, len(families[0].GetMetric()))
+	assert.Equal(t, float64(10), families[0].GetMetric()[0].GetGauge().GetValue())
+}
+
+func TestPrometheus_AddSub(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	s := NewPrometheusStateFactoryWithRegisterer(registry).Create()
+
+	s.SetFloat("section_foo", 1.5)
+	s.Add("section_foo", 2)
+	s.Sub("section_foo", 0.25)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(families))
+	assert.Equal(t, 3.25, families[0].GetMetric()[0].GetGauge().GetValue())
+}
+
+func TestPrometheus_SetSortedLabels(t *testing.T) {
+	m := &GaugeFactoryMock{}
+	s := NewPrometheus(m)
+
+	labels := map[string]string{"c": "3", "a": "1", "d": "4", "b": "2"}
+	for n := 0; n < 50; n++ {
+		s.Set("section_foo", n, labels)
+		assert.Equal(t, []string{"1", "2", "3", "4"}, m.mock.values)
+	}
+	assert.Equal(t, 50, m.mock.withLabelValuesCalls)
+}
+
+func TestPrometheus_SetLabelNamesMismatch(t *testing.T) {
+	m := &GaugeFactoryMock{}
+	s := NewPrometheus(m)
+
+	_, err := s.getGauge("section_foo", []map[string]string{{"key1": "value1", "key2": "value2"}})
+	require.NoError(t, err)
+
+	_, err = s.getGauge("section_foo", []map[string]string{{"key1": "value1"}})
+	assert.Equal(t, ErrLabelNamesMismatch, err)
+
+	_, err = s.getGauge("section_foo", []map[string]string{{"key1": "value1", "key3": "value3"}})
+	assert.Equal(t, ErrLabelNamesMismatch, err)
+	assert.Equal(t, 1, m.mock.withLabelValuesCalls)
+}
+
+func TestPrometheus_SetStableSeries(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	s := NewPrometheusStateFactoryWithRegisterer(registry).Create()
+
+	for n := 0; n < 100; n++ {
+		s.Set("section_foo", 1, map[string]string{"queue": "orders", "region": "eu", "priority": "high"})
+		s.Set("section_foo", 2, map[string]string{"queue": "emails", "region": "us", "priority": "low"})
+	}
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(families))
+	require.Equal(t, 2, len(families[0].GetMetric()))
+	for _, metric := range families[0].GetMetric() {
+		labels := map[string]string{}
+		for _, pair := range metric.GetLabel() {
+			labels[pair.GetName()] = pair.GetValue()
+		}
+
+		if metric.GetGauge().GetValue() == 1 {
+			assert.Equal(t, map[string]string{"queue": "orders", "region": "eu", "priority": "high"}, labels)
+		} else {
+			assert.Equal(t, map[string]string{"queue": "emails", "region": "us", "priority": "low"}, labels)
+		}
+	}
+}
+
+func TestPrometheus_SetCreateFailedOnce(t *testing.T) {
+	m := &FailingGaugeFactoryMock{}
+	s := NewPrometheus(m)
+
+	_, err := s.getGauge("section_foo", []map[string]string{{"key1": "value1"}})
+	assert.Error(t, err)
+
+	// creation is not retried and error is not returned again to be logged once
+	gauge, err := s.getGauge("section_foo", []map[string]string{{"key1": "value1"}})
+	assert.NoError(t, err)
+	assert.Nil(t, gauge)
+
+	s.Set("section_foo", 1, map[string]string{"key1": "value1"})
+	assert.Equal(t, 1, m.calls)
+}
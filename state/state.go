@@ -4,4 +4,13 @@ package state
 type State interface {
 	// Set sets metric state
 	Set(metric string, n int, labels ...map[string]string)
+
+	// SetFloat sets metric state to float value
+	SetFloat(metric string, value float64, labels ...map[string]string)
+
+	// Add increases metric state by delta
+	Add(metric string, delta float64, labels ...map[string]string)
+
+	// Sub decreases metric state by delta
+	Sub(metric string, delta float64, labels ...map[string]string)
 }
@@ -1,18 +1,71 @@
 package state
 
-import "gopkg.in/alexcesaro/statsd.v2"
+import (
+	"strconv"
+
+	"github.com/hellofresh/stats-go/log"
+)
+
+// GaugeWriter writes statsd gauge value as is, it is used for relative gauge updates,
+// e.g. "+5" or "-3.5", that are not supported by statsd client
+type GaugeWriter interface {
+	WriteGauge(metric string, value string)
+}
+
+// StatsDClient is a subset of statsd client methods StatsD state uses, it is implemented by *statsd.Client
+type StatsDClient interface {
+	Gauge(bucket string, value interface{})
+}
 
 // StatsD struct is State interface implementation that writes all states to statsd gauge
 type StatsD struct {
-	c *statsd.Client
+	c      StatsDClient
+	writer GaugeWriter
 }
 
-// NewStatsD creates new statsd state instance
-func NewStatsD(c *statsd.Client) *StatsD {
+// NewStatsD creates new statsd state instance, relative updates are not supported by this instance
+func NewStatsD(c StatsDClient) *StatsD {
 	return &StatsD{c: c}
 }
 
+// NewStatsDWithGaugeWriter creates new statsd state instance that writes relative updates with the given writer
+func NewStatsDWithGaugeWriter(c StatsDClient, writer GaugeWriter) *StatsD {
+	return &StatsD{c: c, writer: writer}
+}
+
 // Set sets metric state
 func (s *StatsD) Set(metric string, n int, labels ...map[string]string) {
 	s.c.Gauge(metric, n)
 }
+
+// SetFloat sets metric state to float value
+func (s *StatsD) SetFloat(metric string, value float64, labels ...map[string]string) {
+	s.c.Gauge(metric, value)
+}
+
+// Add increases metric state by delta using "+<delta>|g" gauge syntax
+func (s *StatsD) Add(metric string, delta float64, labels ...map[string]string) {
+	s.writeDelta(metric, delta)
+}
+
+// Sub decreases metric state by delta using "-<delta>|g" gauge syntax
+func (s *StatsD) Sub(metric string, delta float64, labels ...map[string]string) {
+	s.writeDelta(metric, -delta)
+}
+
+func (s *StatsD) writeDelta(metric string, delta float64) {
+	if s.writer == nil {
+		log.Log("Relative gauge update is not supported without gauge writer", map[string]interface{}{
+			"bucket": metric,
+		}, nil)
+		return
+	}
+
+	// statsd treats signed gauge value as relative update, so positive delta must have explicit sign
+	value := strconv.FormatFloat(delta, 'f', -1, 64)
+	if delta >= 0 {
+		value = "+" + value
+	}
+
+	s.writer.WriteGauge(metric, value)
+}
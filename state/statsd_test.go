@@ -0,0 +1,30 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type gaugeWriterMock struct {
+	metrics []string
+	values  []string
+}
+
+func (w *gaugeWriterMock) WriteGauge(metric string, value string) {
+	w.metrics = append(w.metrics, metric)
+	w.values = append(w.values, value)
+}
+
+func TestStatsD_AddSub(t *testing.T) {
+	w := &gaugeWriterMock{}
+	s := NewStatsDWithGaugeWriter(nil, w)
+
+	s.Add("metric1", 5)
+	s.Add("metric1", 0)
+	s.Sub("metric1", 1.5)
+	s.Sub("metric1", -2)
+
+	assert.Equal(t, []string{"metric1", "metric1", "metric1", "metric1"}, w.metrics)
+	assert.Equal(t, []string{"+5", "+0", "-1.5", "+2"}, w.values)
+}